@@ -1,14 +1,49 @@
 package key_test
 
 import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/asn1"
 	"encoding/base64"
 	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
 	"math/bits"
+	mrand "math/rand/v2"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/svicknesh/key/v2"
 	"github.com/svicknesh/key/v2/asym/ec"
+	"github.com/svicknesh/key/v2/asym/ed"
+	"github.com/svicknesh/key/v2/asym/r"
+	"github.com/svicknesh/key/v2/hd"
+	"github.com/svicknesh/key/v2/keystore"
+	"github.com/svicknesh/key/v2/keystore/keystoretest"
+	"github.com/svicknesh/key/v2/keywrap"
+	"github.com/svicknesh/key/v2/kx/crv"
+	"github.com/svicknesh/key/v2/mnemonic"
+	"github.com/svicknesh/key/v2/rotation"
+	"github.com/svicknesh/key/v2/shamir"
 	"github.com/svicknesh/key/v2/shared"
 	"golang.org/x/crypto/sha3"
 )
@@ -93,58 +128,2956 @@ func testAsymKey(t *testing.T, kt shared.KeyType) {
 		t.Errorf("%s: Verify should fail on tampered payload", kt)
 	}
 
+	// VerifyErr reports why verification failed
+	if err := kPub.VerifyErr(signed, h); err != nil {
+		t.Errorf("%s: VerifyErr on valid signature: %v", kt, err)
+	}
+	if err := kPub.VerifyErr(signed, tampered); !errors.Is(err, key.ErrSignatureMismatch) {
+		t.Errorf("%s: VerifyErr on tampered payload = %v, want ErrSignatureMismatch", kt, err)
+	}
+	if err := kPub.VerifyErr(signed[:len(signed)-1], h); !errors.Is(err, key.ErrMalformedSignature) {
+		t.Errorf("%s: VerifyErr on truncated signature = %v, want ErrMalformedSignature", kt, err)
+	}
+
 	// Sign with public key must return error
 	_, err = kPub.Sign(h)
 	if err == nil {
 		t.Errorf("%s: Sign with public key should return an error", kt)
 	}
 
-	// Verify with private key should return false (no public key set)
-	if k.Verify(signed, h) {
-		t.Errorf("%s: Verify with private key should return false", kt)
+	// Private keys verify using their public half
+	if !k.Verify(signed, h) {
+		t.Errorf("%s: Verify with private key failed on valid signature", kt)
+	}
+	if !k2.Verify(signed, h) {
+		t.Errorf("%s: Verify with string round-trip private key failed on valid signature", kt)
+	}
+	if k.Verify(signed, tampered) {
+		t.Errorf("%s: Verify with private key should fail on tampered payload", kt)
+	}
+	if err := k.VerifyErr(signed, tampered); !errors.Is(err, key.ErrSignatureMismatch) {
+		t.Errorf("%s: VerifyErr with private key on tampered payload = %v, want ErrSignatureMismatch", kt, err)
+	}
+
+	// Message signing hashes internally using the digest for the key type
+	msg := []byte("hello, world")
+	msgSigned, err := k.SignMessage(msg)
+	if err != nil {
+		t.Fatalf("%s: SignMessage: %v", kt, err)
+	}
+	if !kPub.VerifyMessage(msg, msgSigned) {
+		t.Errorf("%s: VerifyMessage failed on valid signature", kt)
+	}
+	if !kPub.VerifyReader(bytes.NewReader(msg), msgSigned) {
+		t.Errorf("%s: VerifyReader failed on signature from SignMessage", kt)
+	}
+	streamSigned, err := k.SignReader(bytes.NewReader(msg))
+	if err != nil {
+		t.Fatalf("%s: SignReader: %v", kt, err)
+	}
+	if !k.VerifyMessage(msg, streamSigned) {
+		t.Errorf("%s: VerifyMessage failed on signature from SignReader", kt)
+	}
+	if kPub.VerifyMessage([]byte("hello, world!"), msgSigned) {
+		t.Errorf("%s: VerifyMessage should fail on tampered message", kt)
+	}
+	if _, err = kPub.SignMessage(msg); err == nil {
+		t.Errorf("%s: SignMessage with public key should return an error", kt)
+	}
+
+	// JSON marshal round-trip
+	jb, err := json.Marshal(k)
+	if err != nil {
+		t.Fatalf("%s: MarshalJSON: %v", kt, err)
+	}
+	kFromJSON, err := key.NewKeyFromBytes(jb)
+	if err != nil {
+		t.Fatalf("%s: NewKeyFromBytes(json): %v", kt, err)
+	}
+	if kFromJSON.KeyType() != kt {
+		t.Errorf("%s: JSON round-trip KeyType = %s, want %s", kt, kFromJSON.KeyType(), kt)
+	}
+
+	// a key marshals to the same JWK when given by value
+	var kv any
+	switch kk := k.(type) {
+	case *ed.K:
+		kv = *kk
+	case *ec.K:
+		kv = *kk
+	case *r.K:
+		kv = *kk
+	}
+	if vb, err := json.Marshal(kv); err != nil || !bytes.Equal(vb, jb) {
+		t.Errorf("%s: MarshalJSON by value = %s, %v, want %s", kt, vb, err, jb)
+	}
+
+	// Public key round-trip via string
+	kPub2, err := key.NewKeyFromStr(kPub.String())
+	if err != nil {
+		t.Fatalf("%s: NewKeyFromStr(public): %v", kt, err)
+	}
+	if !kPub2.IsPublicKey() {
+		t.Errorf("%s: reconstructed public key should be public", kt)
+	}
+}
+
+func TestED25519(t *testing.T)  { testAsymKey(t, key.ED25519) }
+func TestECDSA256(t *testing.T) { testAsymKey(t, key.ECDSA256) }
+func TestECDSA384(t *testing.T) { testAsymKey(t, key.ECDSA384) }
+func TestECDSA521(t *testing.T) { testAsymKey(t, key.ECDSA521) }
+func TestRSA2048(t *testing.T)  { testAsymKey(t, key.RSA2048) }
+
+func TestRSA4096(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping slow RSA4096 generation")
+	}
+	testAsymKey(t, key.RSA4096)
+}
+
+func TestRSA8192(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping slow RSA8192 generation")
+	}
+	testAsymKey(t, key.RSA8192)
+}
+
+func TestVerifyErrNoPublicKey(t *testing.T) {
+	h := hashMsg(t)
+	for _, k := range []key.Key{new(ed.K), new(ec.K), new(r.K)} {
+		if err := k.VerifyErr(make([]byte, 64), h); !errors.Is(err, key.ErrNoPublicKey) {
+			t.Errorf("%T: VerifyErr on empty key = %v, want ErrNoPublicKey", k, err)
+		}
+	}
+}
+
+func TestSignMessageDigest(t *testing.T) {
+	msg := []byte("hello, world")
+
+	cases := []struct {
+		kt   shared.KeyType
+		hash crypto.Hash
+	}{
+		{key.ED25519, 0},
+		{key.ECDSA256, crypto.SHA256},
+		{key.ECDSA384, crypto.SHA384},
+		{key.ECDSA521, crypto.SHA512},
+		{key.RSA2048, crypto.SHA256},
+	}
+	for _, tc := range cases {
+		if got := tc.kt.Hash(); got != tc.hash {
+			t.Errorf("%s: Hash() = %v, want %v", tc.kt, got, tc.hash)
+		}
+
+		k, err := key.GenerateKey(tc.kt)
+		if err != nil {
+			t.Fatalf("GenerateKey(%s): %v", tc.kt, err)
+		}
+		signed, err := k.SignMessage(msg)
+		if err != nil {
+			t.Fatalf("%s: SignMessage: %v", tc.kt, err)
+		}
+
+		// the signature must match one made over the expected digest, or the raw message for ED25519
+		hashed := msg
+		if tc.hash != 0 {
+			h := tc.hash.New()
+			h.Write(msg)
+			hashed = h.Sum(nil)
+		}
+		if !k.Verify(signed, hashed) {
+			t.Errorf("%s: SignMessage signature does not verify against the expected digest", tc.kt)
+		}
+	}
+}
+
+// ---- ED25519 variants (RFC 8032) ----
+
+func mustHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("hex.DecodeString: %v", err)
+	}
+	return b
+}
+
+func TestED25519phRFC8032(t *testing.T) {
+	// RFC 8032, Section 7.3
+	priv := mustHex(t, "833fe62409237b9d62ec77587520911e9a759cec1d19755b7da901b96dca3d42ec172b93ad5e563bf4932c70e1245034c35467ef2efd4d64ebf819683467e2bf")
+	want := mustHex(t, "98a70222f0b8121aa9d30f813d683f809e462b469c7ff87639499bb94e6dae4131f85042463c2a355a2003d062adf5aaa10b8c61e636062aaad11c2a26083406")
+	msg := mustHex(t, "616263")
+
+	k, err := ed.New(ed25519.PrivateKey(priv))
+	if err != nil {
+		t.Fatalf("ed.New: %v", err)
+	}
+
+	digest := sha512.Sum512(msg)
+	signed, err := k.SignWithOptions(digest[:], ed.PhOptions(""))
+	if err != nil {
+		t.Fatalf("SignWithOptions: %v", err)
+	}
+	if !bytes.Equal(signed, want) {
+		t.Errorf("Ed25519ph signature = %x, want %x", signed, want)
+	}
+	if err := k.VerifyWithOptions(want, digest[:], ed.PhOptions("")); err != nil {
+		t.Errorf("VerifyWithOptions: %v", err)
+	}
+
+	// streaming hashes the message as it is read
+	streamed, err := k.SignReaderWithOptions(bytes.NewReader(msg), ed.PhOptions(""))
+	if err != nil {
+		t.Fatalf("SignReaderWithOptions: %v", err)
+	}
+	if !bytes.Equal(streamed, want) {
+		t.Errorf("Ed25519ph streamed signature = %x, want %x", streamed, want)
+	}
+	if err := k.VerifyReaderWithOptions(bytes.NewReader(msg), want, ed.PhOptions("")); err != nil {
+		t.Errorf("VerifyReaderWithOptions: %v", err)
+	}
+
+	// Ed25519ph signatures do not verify as pure ED25519 or with a different context
+	if k.Verify(want, digest[:]) {
+		t.Error("Ed25519ph signature should not verify as pure ED25519")
+	}
+	if err := k.VerifyWithOptions(want, digest[:], ed.PhOptions("other")); !errors.Is(err, key.ErrSignatureMismatch) {
+		t.Errorf("VerifyWithOptions with wrong context = %v, want ErrSignatureMismatch", err)
+	}
+}
+
+func TestED25519ctxRFC8032(t *testing.T) {
+	// RFC 8032, Section 7.2
+	priv := mustHex(t, "0305334e381af78f141cb666f6199f57bc3495335a256a95bd2a55bf546663f6dfc9425e4f968f7f0c29f0259cf5f9aed6851c2bb4ad8bfb860cfee0ab248292")
+	want := mustHex(t, "55a4cc2f70a54e04288c5f4cd1e45a7bb520b36292911876cada7323198dd87a8b36950b95130022907a7fb7c4e9b2d5f6cca685a587b4b21f4b888e4e7edb0d")
+	msg := mustHex(t, "f726936d19c800494e3fdaff20b276a8")
+
+	k, err := ed.New(ed25519.PrivateKey(priv))
+	if err != nil {
+		t.Fatalf("ed.New: %v", err)
+	}
+	kPub, err := k.PublicKey()
+	if err != nil {
+		t.Fatalf("PublicKey: %v", err)
+	}
+
+	foo, err := ed.CtxOptions("foo")
+	if err != nil {
+		t.Fatalf("CtxOptions: %v", err)
+	}
+	bar, _ := ed.CtxOptions("bar")
+
+	signed, err := k.SignWithOptions(msg, foo)
+	if err != nil {
+		t.Fatalf("SignWithOptions: %v", err)
+	}
+	if !bytes.Equal(signed, want) {
+		t.Errorf("Ed25519ctx signature = %x, want %x", signed, want)
+	}
+	if err := kPub.(*ed.K).VerifyWithOptions(want, msg, foo); err != nil {
+		t.Errorf("VerifyWithOptions: %v", err)
+	}
+	if err := kPub.(*ed.K).VerifyWithOptions(want, msg, bar); !errors.Is(err, key.ErrSignatureMismatch) {
+		t.Errorf("VerifyWithOptions with wrong context = %v, want ErrSignatureMismatch", err)
+	}
+	if _, err := k.SignWithOptions(msg, &ed25519.Options{Hash: crypto.SHA256}); err == nil {
+		t.Error("SignWithOptions should reject hashes other than SHA-512")
+	}
+
+	// an empty context would silently select plain Ed25519
+	if opts, err := ed.CtxOptions(""); err == nil {
+		t.Errorf("CtxOptions with an empty context = %+v, want an error", opts)
+	}
+}
+
+// ---- Deterministic ECDSA (RFC 6979) ----
+
+func TestECDSADeterministicRFC6979(t *testing.T) {
+	// RFC 6979, Appendix A.2.5 - A.2.7, SHA-256
+	cases := []struct {
+		name          string
+		curve         elliptic.Curve
+		d, x, y, r, s string
+	}{
+		{
+			"P-256", elliptic.P256(),
+			"C9AFA9D845BA75166B5C215767B1D6934E50C3DB36E89B127B8A622B120F6721",
+			"60FED4BA255A9D31C961EB74C6356D68C049B8923B61FA6CE669622E60F29FB6",
+			"7903FE1008B8BC99A41AE9E95628BC64F2F1B20C2D7E9F5177A3C294D4462299",
+			"EFD48B2AACB6A8FD1140DD9CD45E81D69D2C877B56AAF991C34D0EA84EAF3716",
+			"F7CB1C942D657C41D436C7A1B6E29F65F3E900DBB9AFF4064DC4AB2F843ACDA8",
+		},
+		{
+			"P-384", elliptic.P384(),
+			"6B9D3DAD2E1B8C1C05B19875B6659F4DE23C3B667BF297BA9AA47740787137D896D5724E4C70A825F872C9EA60D2EDF5",
+			"EC3A4E415B4E19A4568618029F427FA5DA9A8BC4AE92E02E06AAE5286B300C64DEF8F0EA9055866064A254515480BC13",
+			"8015D9B72D7D57244EA8EF9AC0C621896708A59367F9DFB9F54CA84B3F1C9DB1288B231C3AE0D4FE7344FD2533264720",
+			"21B13D1E013C7FA1392D03C5F99AF8B30C570C6F98D4EA8E354B63A21D3DAA33BDE1E888E63355D92FA2B3C36D8FB2CD",
+			"F3AA443FB107745BF4BD77CB3891674632068A10CA67E3D45DB2266FA7D1FEEBEFDC63ECCD1AC42EC0CB8668A4FA0AB0",
+		},
+		{
+			"P-521", elliptic.P521(),
+			"0FAD06DAA62BA3B25D2FB40133DA757205DE67F5BB0018FEE8C86E1B68C7E75CAA896EB32F1F47C70855836A6D16FCC1466F6D8FBEC67DB89EC0C08B0E996B83538",
+			"1894550D0785932E00EAA23B694F213F8C3121F86DC97A04E5A7167DB4E5BCD371123D46E45DB6B5D5370A7F20FB633155D38FFA16D2BD761DCAC474B9A2F5023A4",
+			"0493101C962CD4D2FDDF782285E64584139C2F91B47F87FF82354D6630F746A28A0DB25741B5B34A828008B22ACC23F924FAAFBD4D33F81EA66956DFEAA2BFDFCF5",
+			"1511BB4D675114FE266FC4372B87682BAECC01D3CC62CF2303C92B3526012659D16876E25C7C1E57648F23B73564D67F61C6F14D527D54972810421E7D87589E1A7",
+			"04A171143A83163D6DF460AAF61522695F207A58B95C0644D87E52AA1A347916E4F7A72930B1BC06DBE22CE3F58264AFD23704CBB63B29B931F7DE6C9D949A7ECFC",
+		},
+	}
+
+	fromHex := func(s string) *big.Int {
+		n, ok := new(big.Int).SetString(s, 16)
+		if !ok {
+			t.Fatalf("invalid hex %q", s)
+		}
+		return n
+	}
+
+	h := sha256.Sum256([]byte("sample"))
+
+	for _, tc := range cases {
+		priv := &ecdsa.PrivateKey{
+			D:         fromHex(tc.d),
+			PublicKey: ecdsa.PublicKey{Curve: tc.curve, X: fromHex(tc.x), Y: fromHex(tc.y)},
+		}
+		k, err := ec.New(priv)
+		if err != nil {
+			t.Fatalf("%s: ec.New: %v", tc.name, err)
+		}
+
+		// per call
+		signed, err := k.SignDeterministic(h[:])
+		if err != nil {
+			t.Fatalf("%s: SignDeterministic: %v", tc.name, err)
+		}
+
+		var sig struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(signed, &sig); err != nil {
+			t.Fatalf("%s: asn1.Unmarshal: %v", tc.name, err)
+		}
+		if sig.R.Cmp(fromHex(tc.r)) != 0 || sig.S.Cmp(fromHex(tc.s)) != 0 {
+			t.Errorf("%s: signature (r=%X, s=%X), want (r=%s, s=%s)", tc.name, sig.R, sig.S, tc.r, tc.s)
+		}
+
+		// per key
+		k.SetDeterministic(true)
+		signed2, err := k.Sign(h[:])
+		if err != nil {
+			t.Fatalf("%s: Sign: %v", tc.name, err)
+		}
+		if !bytes.Equal(signed, signed2) {
+			t.Errorf("%s: Sign with SetDeterministic(true) differs from SignDeterministic", tc.name)
+		}
+		if !k.Verify(signed2, h[:]) {
+			t.Errorf("%s: Verify failed on deterministic signature", tc.name)
+		}
+	}
+}
+
+// ---- ECDSA signature encoding ----
+
+func TestECDSARawEncoding(t *testing.T) {
+	sizes := map[shared.KeyType]int{key.ECDSA256: 32, key.ECDSA384: 48, key.ECDSA521: 66}
+	h := hashMsg(t)
+
+	for kt, size := range sizes {
+		k, err := ec.Generate(kt)
+		if err != nil {
+			t.Fatalf("ec.Generate(%s): %v", kt, err)
+		}
+		k.SetEncoding(ec.EncodingRaw)
+
+		raw, err := k.Sign(h)
+		if err != nil {
+			t.Fatalf("%s: Sign: %v", kt, err)
+		}
+		if len(raw) != 2*size {
+			t.Errorf("%s: raw signature length = %d, want %d", kt, len(raw), 2*size)
+		}
+
+		// the public key inherits the encoding
+		kPub, err := k.PublicKey()
+		if err != nil {
+			t.Fatalf("%s: PublicKey: %v", kt, err)
+		}
+		if err := kPub.VerifyErr(raw, h); err != nil {
+			t.Errorf("%s: VerifyErr on raw signature: %v", kt, err)
+		}
+
+		// converted signatures verify with the default ASN.1 encoding
+		der, err := ec.RawToASN1(raw, kt)
+		if err != nil {
+			t.Fatalf("%s: RawToASN1: %v", kt, err)
+		}
+		kASN1, err := key.NewKeyFromStr(kPub.String())
+		if err != nil {
+			t.Fatalf("%s: NewKeyFromStr: %v", kt, err)
+		}
+		if !kASN1.Verify(der, h) {
+			t.Errorf("%s: Verify failed on signature converted to ASN.1", kt)
+		}
+		if kASN1.Verify(raw, h) {
+			t.Errorf("%s: ASN.1 key should not verify a raw signature", kt)
+		}
+
+		back, err := ec.ASN1ToRaw(der, kt)
+		if err != nil {
+			t.Fatalf("%s: ASN1ToRaw: %v", kt, err)
+		}
+		if !bytes.Equal(back, raw) {
+			t.Errorf("%s: ASN.1 -> raw round-trip mismatch", kt)
+		}
+
+		// a raw signature of the wrong size is malformed
+		if err := kPub.VerifyErr(raw[1:], h); !errors.Is(err, key.ErrMalformedSignature) {
+			t.Errorf("%s: VerifyErr on short raw signature = %v, want ErrMalformedSignature", kt, err)
+		}
+	}
+
+	if _, err := ec.RawToASN1(make([]byte, 64), key.ED25519); err == nil {
+		t.Error("RawToASN1 should reject non ECDSA key types")
+	}
+}
+
+// ---- Injectable randomness ----
+
+// seededRand returns a reproducible source of randomness for test fixtures.
+func seededRand() *mrand.ChaCha8 {
+	return mrand.NewChaCha8([32]byte{1, 2, 3, 4})
+}
+
+func TestGenerateKeyWithRand(t *testing.T) {
+	for _, kt := range []shared.KeyType{key.ED25519, key.ECDSA256, key.ECDSA384, key.ECDSA521} {
+		a, err := key.GenerateKey(kt, key.WithRand(seededRand()))
+		if err != nil {
+			t.Fatalf("GenerateKey(%s, WithRand): %v", kt, err)
+		}
+		b, err := key.GenerateKey(kt, key.WithRand(seededRand()))
+		if err != nil {
+			t.Fatalf("GenerateKey(%s, WithRand): %v", kt, err)
+		}
+		if a.String() != b.String() {
+			t.Errorf("%s: keys generated from the same randomness differ", kt)
+		}
+
+		c, err := key.GenerateKey(kt)
+		if err != nil {
+			t.Fatalf("GenerateKey(%s): %v", kt, err)
+		}
+		if a.String() == c.String() {
+			t.Errorf("%s: key from default randomness matches seeded key", kt)
+		}
+
+		// signing accepts the same option
+		h := hashMsg(t)
+		signed, err := a.Sign(h, key.WithRand(seededRand()))
+		if err != nil {
+			t.Fatalf("%s: Sign(WithRand): %v", kt, err)
+		}
+		if !b.Verify(signed, h) {
+			t.Errorf("%s: Verify failed on signature made with WithRand", kt)
+		}
+	}
+}
+
+func TestGenerateKeyExchangeWithRand(t *testing.T) {
+	for _, kxt := range []shared.KeyXType{key.CURVE25519, key.ECDH256, key.ECDH384, key.ECDH521} {
+		a, err := key.GenerateKeyExchange(kxt, key.WithRand(seededRand()))
+		if err != nil {
+			t.Fatalf("GenerateKeyExchange(%s, WithRand): %v", kxt, err)
+		}
+		b, err := key.GenerateKeyExchange(kxt, key.WithRand(seededRand()))
+		if err != nil {
+			t.Fatalf("GenerateKeyExchange(%s, WithRand): %v", kxt, err)
+		}
+		if a.String() != b.String() {
+			t.Errorf("%s: key exchanges generated from the same randomness differ", kxt)
+		}
+	}
+}
+
+func TestGenerateKeyWithFailingRand(t *testing.T) {
+	if _, err := key.GenerateKey(key.ECDSA256, key.WithRand(bytes.NewReader(nil))); err == nil {
+		t.Error("GenerateKey should fail when the randomness source is exhausted")
+	}
+	if _, err := key.GenerateKeyExchange(key.CURVE25519, key.WithRand(bytes.NewReader(nil))); err == nil {
+		t.Error("GenerateKeyExchange should fail when the randomness source is exhausted")
+	}
+
+	// a source that only produces zeros never yields a valid scalar
+	zeros := bytes.NewReader(make([]byte, 1<<16))
+	if _, err := key.GenerateKeyExchange(key.ECDH256, key.WithRand(zeros)); err == nil {
+		t.Error("GenerateKeyExchange should fail when the randomness source only produces zeros")
+	}
+}
+
+// ---- Deterministic derivation from a seed ----
+
+func testSeed() []byte {
+	seed := make([]byte, key.SeedSize)
+	for i := range seed {
+		seed[i] = byte(i)
+	}
+	return seed
+}
+
+func TestGenerateKeyFromSeed(t *testing.T) {
+	seed := testSeed()
+
+	for _, kt := range []shared.KeyType{key.ED25519, key.ECDSA256, key.ECDSA384, key.ECDSA521} {
+		a, err := key.GenerateKeyFromSeed(kt, seed, "tenant-1")
+		if err != nil {
+			t.Fatalf("GenerateKeyFromSeed(%s): %v", kt, err)
+		}
+		b, err := key.GenerateKeyFromSeed(kt, seed, "tenant-1")
+		if err != nil {
+			t.Fatalf("GenerateKeyFromSeed(%s): %v", kt, err)
+		}
+		if a.KeyType() != kt || !a.IsPrivateKey() {
+			t.Errorf("%s: derived key has type %s, private=%t", kt, a.KeyType(), a.IsPrivateKey())
+		}
+		if a.String() != b.String() {
+			t.Errorf("%s: same seed and label produced different keys", kt)
+		}
+
+		c, err := key.GenerateKeyFromSeed(kt, seed, "tenant-2")
+		if err != nil {
+			t.Fatalf("GenerateKeyFromSeed(%s): %v", kt, err)
+		}
+		if a.String() == c.String() {
+			t.Errorf("%s: different labels produced the same key", kt)
+		}
+	}
+
+	// known answers, changing these breaks recovery of every previously derived key
+	vectors := []struct {
+		kt  shared.KeyType
+		pub string
+	}{
+		{key.ED25519, `{"crv":"Ed25519","kid":"ddoFC23Al3MJKQfR8cBswxmWEYe2PjHPuoKwASjIXJM","kty":"OKP","x":"E0wlKGVoVHBmd4oZbA5IMCY0W5mBOIOEB3wGDBOktdQ"}`},
+		{key.ECDSA256, `{"crv":"P-256","kid":"HWDxHBkAL5JtbMQwHhNrnPqjxsmx_ZI_msuBuvRoh2E","kty":"EC","x":"KvfK5QVSOa6yzywixnWTzfTypp4uFjatLz1iJLJPbak","y":"Xrb9XdWPpu9kt9bCL0XrGGA6kQBNS8hKL7OJK12UqpY"}`},
+		{key.ECDSA384, `{"crv":"P-384","kid":"RLBARP6l7DFO7V7-19Seil3pK_PtWEEwjOyf8jHcJEM","kty":"EC","x":"bRA0kaAbhi2O5Ke5lea0sQVoQ43Ptwmc2xS-EzwmFupsASxzY9BsMcddNC9tC_Et","y":"CPf9Tso3zeI_JOnxL2DJGU1aJY8Mw9SqAqP106wh9-_LUlc-S7qDiq1Ph64OQLaD"}`},
+		{key.ECDSA521, `{"crv":"P-521","kid":"0J9Q0Ol1lIZQ6d8XTF_lAal88G6hkWjgZyXUZbAM_oU","kty":"EC","x":"AfkiOzYlab-UCLvAwc5bBVFeWiO8cUDfNyC8uCXwxW7bOoIglU1G_BH1olbPGWEuM1fZxJeaE-YbGjIIlpr-bMcU","y":"ACi7zXIcHgz2cdOxf53UlqauN8IXhcwVQbYZAjbLjvghUJZLuOy-XEhvP87JQRuDCp311b1JQdEfOpNnAUZsBwZn"}`},
+	}
+	for _, v := range vectors {
+		k, err := key.GenerateKeyFromSeed(v.kt, seed, "tenant-1")
+		if err != nil {
+			t.Fatalf("GenerateKeyFromSeed(%s): %v", v.kt, err)
+		}
+		pub, err := k.PublicKey()
+		if err != nil {
+			t.Fatalf("PublicKey: %v", err)
+		}
+		if pub.String() != v.pub {
+			t.Errorf("derived %s public key = %s, want %s", v.kt, pub, v.pub)
+		}
+	}
+
+	if _, err := key.GenerateKeyFromSeed(key.RSA2048, seed, "tenant-1"); err == nil {
+		t.Error("GenerateKeyFromSeed should reject RSA")
+	}
+	if _, err := key.GenerateKeyFromSeed(key.ED25519, seed[:16], "tenant-1"); err == nil {
+		t.Error("GenerateKeyFromSeed should reject a short seed")
+	}
+}
+
+func TestGenerateKeyExchangeFromSeed(t *testing.T) {
+	seed := testSeed()
+
+	for _, kxt := range []shared.KeyXType{key.CURVE25519, key.ECDH256, key.ECDH384, key.ECDH521} {
+		a, err := key.GenerateKeyExchangeFromSeed(kxt, seed, "tenant-1")
+		if err != nil {
+			t.Fatalf("GenerateKeyExchangeFromSeed(%s): %v", kxt, err)
+		}
+		b, err := key.GenerateKeyExchangeFromSeed(kxt, seed, "tenant-1")
+		if err != nil {
+			t.Fatalf("GenerateKeyExchangeFromSeed(%s): %v", kxt, err)
+		}
+		if a.String() != b.String() {
+			t.Errorf("%s: same seed and label produced different key exchanges", kxt)
+		}
+		c, err := key.GenerateKeyExchangeFromSeed(kxt, seed, "tenant-2")
+		if err != nil {
+			t.Fatalf("GenerateKeyExchangeFromSeed(%s): %v", kxt, err)
+		}
+		if a.String() == c.String() {
+			t.Errorf("%s: different labels produced the same key exchange", kxt)
+		}
+	}
+
+	// known answers, changing these breaks recovery of every previously derived key exchange
+	kxVectors := []struct {
+		kxt shared.KeyXType
+		pub string
+	}{
+		{key.CURVE25519, "ypgAtH9JMOcwZelm1Oz9p9e3SJBqEN1hS9wMJUSVszUE"},
+		{key.ECDH256, "1ATWP-RfTmIvNqwr_BAua_w43ZtAi3HUK63GDEtDrKfN1EI1ETLfCMT21JTiV_WSlwXvYWM3uAM2lgZ4zsS0R2S4"},
+		{key.ECDH384, "1gRj7GnrC8pTu1Ii-MZ5xrskln-c2YQRq1HahCE9kbizhpY1NKSwyYUa07dEmcXz-f_CZU1SNjVGlklDjg69mE4oQ4-pxb7YF1rVvqA8ZzdVr2PA-XpHgHz5kknb66Df90I="},
+		{key.ECDH521, "2AQBUzKO1G321qZ0N1UjTpH3YZkpMzXGjbQZcviYwT76fTMNUP1kMUmUJmAGcfhvXrO0q3Q-f-zC5FY5b7WhAS_U0qcAOrgb9bXhwoDPzmJyDxxjuf59D0jlp_Dz6KB4G0y9IzSH0X6a924chCwMFSGGDxsbfldjnxY1TTAc6inAV70JmN0="},
+	}
+	for _, v := range kxVectors {
+		kx, err := key.GenerateKeyExchangeFromSeed(v.kxt, seed, "tenant-1")
+		if err != nil {
+			t.Fatalf("GenerateKeyExchangeFromSeed(%s): %v", v.kxt, err)
+		}
+		if got := kx.PublicKey().String(); got != v.pub {
+			t.Errorf("derived %s public key = %s, want %s", v.kxt, got, v.pub)
+		}
+	}
+
+	kx, _ := key.GenerateKeyExchangeFromSeed(key.CURVE25519, seed, "tenant-1")
+	// the same seed and label give unrelated keys across key and key exchange types
+	edk, _ := key.GenerateKeyFromSeed(key.ED25519, seed, "tenant-1")
+	edSeed := edk.PrivateKeyInstance().(ed25519.PrivateKey).Seed()
+	kxPriv, _ := kx.Bytes()
+	if bytes.Equal(edSeed, kxPriv[1:]) {
+		t.Error("ED25519 and CURVE25519 derived from the same seed and label share private material")
+	}
+}
+
+// ---- BIP-39 mnemonics ----
+
+func TestMnemonicVectors(t *testing.T) {
+	// BIP-39 reference vectors (English)
+	cases := []struct{ entropy, words string }{
+		{"00000000000000000000000000000000", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"},
+		{"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f", "legal winner thank year wave sausage worth useful legal winner thank yellow"},
+		{"ffffffffffffffffffffffffffffffff", "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong"},
+		{"808080808080808080808080808080808080808080808080", "letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic avoid letter always"},
+		{"0000000000000000000000000000000000000000000000000000000000000000", "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art"},
+		{"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote"},
+		{"77c2b00716cec7213839159e404db50d", "jelly better achieve collect unaware mountain thought cargo oxygen act hood bridge"},
+		{"3e141609b97933b66a060dcddc71fad1d91677db872031e85f4c015c5e7e8982", "dignity pass list indicate nasty swamp pool script soccer toe leaf photo multiply desk host tomato cradle drill spread actor shine dismiss champion exotic"},
+	}
+	for _, tc := range cases {
+		entropy := mustHex(t, tc.entropy)
+		words, err := mnemonic.New(entropy)
+		if err != nil {
+			t.Fatalf("mnemonic.New(%s): %v", tc.entropy, err)
+		}
+		if words != tc.words {
+			t.Errorf("mnemonic.New(%s) = %q, want %q", tc.entropy, words, tc.words)
+		}
+		back, err := mnemonic.Entropy(tc.words)
+		if err != nil {
+			t.Fatalf("mnemonic.Entropy(%q): %v", tc.words, err)
+		}
+		if !bytes.Equal(back, entropy) {
+			t.Errorf("mnemonic.Entropy(%q) = %x, want %s", tc.words, back, tc.entropy)
+		}
+	}
+
+	// a valid word in the wrong place breaks the checksum
+	if _, err := mnemonic.Entropy("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon"); !errors.Is(err, mnemonic.ErrChecksum) {
+		t.Errorf("Entropy with bad checksum = %v, want ErrChecksum", err)
+	}
+	if mnemonic.Valid("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon notaword") {
+		t.Error("Valid should reject unknown words")
+	}
+	if _, err := mnemonic.New(make([]byte, 15)); err == nil {
+		t.Error("New should reject entropy that is not a multiple of 4 bytes")
+	}
+}
+
+func TestKeyMnemonic(t *testing.T) {
+	k, err := key.GenerateKey(key.ED25519)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	words, err := key.MnemonicFromKey(k)
+	if err != nil {
+		t.Fatalf("MnemonicFromKey: %v", err)
+	}
+	if n := len(strings.Fields(words)); n != 24 {
+		t.Errorf("mnemonic has %d words, want 24", n)
+	}
+
+	k2, err := key.NewKeyFromMnemonic(key.ED25519, "  "+strings.ToUpper(words)+"\n")
+	if err != nil {
+		t.Fatalf("NewKeyFromMnemonic: %v", err)
+	}
+	if k.String() != k2.String() {
+		t.Error("key restored from mnemonic differs from the original")
+	}
+
+	ecKey, _ := key.GenerateKey(key.ECDSA256)
+	if _, err := key.MnemonicFromKey(ecKey); err == nil {
+		t.Error("MnemonicFromKey should reject ECDSA keys")
+	}
+	kPub, _ := k.PublicKey()
+	if _, err := key.MnemonicFromKey(kPub); err == nil {
+		t.Error("MnemonicFromKey should reject public keys")
+	}
+}
+
+func TestKXMnemonic(t *testing.T) {
+	kx, err := key.GenerateKeyExchange(key.CURVE25519)
+	if err != nil {
+		t.Fatalf("GenerateKeyExchange: %v", err)
+	}
+	words, err := key.MnemonicFromKX(kx)
+	if err != nil {
+		t.Fatalf("MnemonicFromKX: %v", err)
+	}
+	kx2, err := key.NewKXFromMnemonic(key.CURVE25519, words)
+	if err != nil {
+		t.Fatalf("NewKXFromMnemonic: %v", err)
+	}
+	if kx.String() != kx2.String() {
+		t.Error("key exchange restored from mnemonic differs from the original")
+	}
+}
+
+func TestSeedMnemonic(t *testing.T) {
+	seed := testSeed()
+	words, err := mnemonic.New(seed)
+	if err != nil {
+		t.Fatalf("mnemonic.New: %v", err)
+	}
+
+	k, err := key.GenerateKeyFromMnemonic(key.ECDSA256, words, "tenant-1")
+	if err != nil {
+		t.Fatalf("GenerateKeyFromMnemonic: %v", err)
+	}
+	want, _ := key.GenerateKeyFromSeed(key.ECDSA256, seed, "tenant-1")
+	if k.String() != want.String() {
+		t.Error("key derived from seed mnemonic differs from key derived from seed")
+	}
+
+	kx, err := key.GenerateKeyExchangeFromMnemonic(key.CURVE25519, words, "tenant-1")
+	if err != nil {
+		t.Fatalf("GenerateKeyExchangeFromMnemonic: %v", err)
+	}
+	wantKX, _ := key.GenerateKeyExchangeFromSeed(key.CURVE25519, seed, "tenant-1")
+	if kx.String() != wantKX.String() {
+		t.Error("key exchange derived from seed mnemonic differs from key exchange derived from seed")
+	}
+
+	// a valid 12 word mnemonic holds only 16 bytes, too short for a master seed
+	short, err := mnemonic.New(seed[:16])
+	if err != nil {
+		t.Fatalf("mnemonic.New: %v", err)
+	}
+	if _, err := key.GenerateKeyFromMnemonic(key.ECDSA256, short, "tenant-1"); err == nil || !strings.Contains(err.Error(), "12 words") {
+		t.Errorf("expected GenerateKeyFromMnemonic to reject a 12 word mnemonic, got %v", err)
+	}
+	if _, err := key.GenerateKeyExchangeFromMnemonic(key.CURVE25519, short, "tenant-1"); err == nil || !strings.Contains(err.Error(), "12 words") {
+		t.Errorf("expected GenerateKeyExchangeFromMnemonic to reject a 12 word mnemonic, got %v", err)
+	}
+}
+
+// ---- Hierarchical deterministic derivation (SLIP-0010) ----
+
+type hdVector struct {
+	path, chainCode, priv, pub string
+}
+
+func testHDVectors(t *testing.T, kt shared.KeyType, vectors []hdVector) {
+	t.Helper()
+	master, err := hd.NewMaster(kt, mustHex(t, "000102030405060708090a0b0c0d0e0f"))
+	if err != nil {
+		t.Fatalf("NewMaster: %v", err)
+	}
+
+	for _, v := range vectors {
+		n, err := master.Derive(v.path)
+		if err != nil {
+			t.Fatalf("Derive(%s): %v", v.path, err)
+		}
+		if got := hex.EncodeToString(n.ChainCode()); got != v.chainCode {
+			t.Errorf("%s chain code = %s, want %s", v.path, got, v.chainCode)
+		}
+		if got := hex.EncodeToString(n.PrivateKeyBytes()); got != v.priv {
+			t.Errorf("%s private key = %s, want %s", v.path, got, v.priv)
+		}
+		if got := hex.EncodeToString(n.PublicKeyBytes()); got != v.pub {
+			t.Errorf("%s public key = %s, want %s", v.path, got, v.pub)
+		}
+
+		// non-hardened children are also derived from the public parent
+		i := strings.LastIndex(v.path, "/")
+		if i < 0 || strings.HasSuffix(v.path, "'") {
+			continue
+		}
+		parent, err := master.Derive(v.path[:i])
+		if err != nil {
+			t.Fatalf("Derive(%s): %v", v.path[:i], err)
+		}
+		xpub, err := parent.Neuter()
+		if err != nil {
+			t.Fatalf("Neuter: %v", err)
+		}
+		pub, err := xpub.Derive(v.path[i+1:])
+		if err != nil {
+			t.Fatalf("public Derive(%s): %v", v.path, err)
+		}
+		if got := hex.EncodeToString(pub.PublicKeyBytes()); got != v.pub {
+			t.Errorf("%s public key derived from the public parent = %s, want %s", v.path, got, v.pub)
+		}
+		if got := hex.EncodeToString(pub.ChainCode()); got != v.chainCode {
+			t.Errorf("%s chain code derived from the public parent = %s, want %s", v.path, got, v.chainCode)
+		}
+	}
+}
+
+func TestHDED25519SLIP10(t *testing.T) {
+	testHDVectors(t, key.ED25519, []hdVector{
+		{"m", "90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb", "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7", "00a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed"},
+		{"m/0'", "8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69", "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3", "008c8a13df77a28f3445213a0f432fde644acaa215fc72dcdf300d5efaa85d350c"},
+		{"m/0'/1'", "a320425f77d1b5c2505a6b1b27382b37368ee640e3557c315416801243552f14", "b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2", "001932a5270f335bed617d5b935c80aedb1a35bd9fc1e31acafd5372c30f5c1187"},
+		{"m/0'/1'/2'", "2e69929e00b5ab250f49c3fb1c12f252de4fed2c1db88387094a0f8c4c9ccd6c", "92a5b23c0b8a99e37d07df3fb9966917f5d06e02ddbd909c7e184371463e9fc9", "00ae98736566d30ed0e9d2f4486a64bc95740d89c7db33f52121f8ea8f76ff0fc1"},
+		{"m/0'/1'/2'/2'", "8f6d87f93d750e0efccda017d662a1b31a266e4a6f5993b15f5c1f07f74dd5cc", "30d1dc7e5fc04c31219ab25a27ae00b50f6fd66622f6e9c913253d6511d1e662", "008abae2d66361c879b900d204ad2cc4984fa2aa344dd7ddc46007329ac76c429c"},
+		{"m/0'/1'/2'/2'/1000000000'", "68789923a0cac2cd5a29172a475fe9e0fb14cd6adb5ad98a3fa70333e7afa230", "8f94d394a8e8fd6b1bc2f3f49f5c47e385281d5c17e65324b0f62483e37e8793", "003c24da049451555d51a7014a37337aa4e12d41e485abccfa46b47dfb2af54b7a"},
+	})
+}
+
+func TestHDP256SLIP10(t *testing.T) {
+	testHDVectors(t, key.ECDSA256, []hdVector{
+		{"m", "beeb672fe4621673f722f38529c07392fecaa61015c80c34f29ce8b41b3cb6ea", "612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2", "0266874dc6ade47b3ecd096745ca09bcd29638dd52c2c12117b11ed3e458cfa9e8"},
+		{"m/0'", "3460cea53e6a6bb5fb391eeef3237ffd8724bf0a40e94943c98b83825342ee11", "6939694369114c67917a182c59ddb8cafc3004e63ca5d3b84403ba8613debc0c", "0384610f5ecffe8fda089363a41f56a5c7ffc1d81b59a612d0d649b2d22355590c"},
+		{"m/0'/1", "4187afff1aafa8445010097fb99d23aee9f599450c7bd140b6826ac22ba21d0c", "284e9d38d07d21e4e281b645089a94f4cf5a5a81369acf151a1c3a57f18b2129", "03526c63f8d0b4bbbf9c80df553fe66742df4676b241dabefdef67733e070f6844"},
+		{"m/0'/1/2'", "98c7514f562e64e74170cc3cf304ee1ce54d6b6da4f880f313e8204c2a185318", "694596e8a54f252c960eb771a3c41e7e32496d03b954aeb90f61635b8e092aa7", "0359cf160040778a4b14c5f4d7b76e327ccc8c4a6086dd9451b7482b5a4972dda0"},
+		{"m/0'/1/2'/2", "ba96f776a5c3907d7fd48bde5620ee374d4acfd540378476019eab70790c63a0", "5996c37fd3dd2679039b23ed6f70b506c6b56b3cb5e424681fb0fa64caf82aaa", "029f871f4cb9e1c97f9f4de9ccd0d4a2f2a171110c61178f84430062230833ff20"},
+		{"m/0'/1/2'/2/1000000000", "b9b7b82d326bb9cb5b5b121066feea4eb93d5241103c9e7a18aad40f1dde8059", "21c4f269ef0a5fd1badf47eeacebeeaa3de22eb8e5b0adcd0f27dd99d34d0119", "02216cd26d31147f72427a453c443ed2cde8a1e53c9cc44e5ddf739725413fe3f4"},
+	})
+}
+
+func TestHDExtendedPublicKey(t *testing.T) {
+	master, err := hd.NewMaster(key.ECDSA256, testSeed())
+	if err != nil {
+		t.Fatalf("NewMaster: %v", err)
+	}
+	account, err := master.Derive("m/44'/0'")
+	if err != nil {
+		t.Fatalf("Derive: %v", err)
+	}
+	xpub, err := account.Neuter()
+	if err != nil {
+		t.Fatalf("Neuter: %v", err)
+	}
+	if xpub.IsPrivate() {
+		t.Error("neutered node should not be private")
+	}
+
+	priv, err := account.Derive("0/7")
+	if err != nil {
+		t.Fatalf("Derive private child: %v", err)
+	}
+	pub, err := xpub.Derive("0/7")
+	if err != nil {
+		t.Fatalf("Derive public child: %v", err)
+	}
+	if !bytes.Equal(priv.PublicKeyBytes(), pub.PublicKeyBytes()) {
+		t.Error("public child derived from extended public key differs from private child")
+	}
+
+	// a signature from the private child verifies with the key from the public child
+	kPriv, err := priv.Key()
+	if err != nil {
+		t.Fatalf("Key: %v", err)
+	}
+	kPub, err := pub.Key()
+	if err != nil {
+		t.Fatalf("Key: %v", err)
+	}
+	if !kPub.IsPublicKey() {
+		t.Error("key from public node should be a public key")
+	}
+	signed, err := kPriv.SignMessage([]byte("hello, world"))
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+	if !kPub.VerifyMessage([]byte("hello, world"), signed) {
+		t.Error("public child key failed to verify signature from private child key")
+	}
+
+	if _, err := xpub.Derive("0'"); err == nil {
+		t.Error("expected error for hardened derivation from extended public key")
+	}
+	if _, err := xpub.Derive("m/0"); err == nil {
+		t.Error("expected error for absolute path from non-master node")
+	}
+}
+
+func TestHDDeriveKey(t *testing.T) {
+	k, err := hd.DeriveKey(key.ED25519, testSeed(), "m/44'/0'/3'")
+	if err != nil {
+		t.Fatalf("DeriveKey: %v", err)
+	}
+	k2, _ := hd.DeriveKey(key.ED25519, testSeed(), "m/44h/0h/3h")
+	if k.String() != k2.String() {
+		t.Error("hardened markers ' and h should derive the same key")
+	}
+	k3, _ := hd.DeriveKey(key.ED25519, testSeed(), "m/44'/0'/4'")
+	if k.String() == k3.String() {
+		t.Error("different paths derived the same key")
+	}
+	signed, err := k.SignMessage([]byte("hello, world"))
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+	if !k.VerifyMessage([]byte("hello, world"), signed) {
+		t.Error("derived key failed to verify its own signature")
+	}
+
+	if _, err := hd.DeriveKey(key.ED25519, testSeed(), "m/44'/0"); !errors.Is(err, hd.ErrHardenedOnly) {
+		t.Errorf("expected ErrHardenedOnly for non-hardened ED25519 derivation, got %v", err)
+	}
+	for _, kt := range []key.KeyType{key.RSA2048, key.ECDSA384, key.ECDSA521} {
+		if _, err := hd.DeriveKey(kt, testSeed(), "m/0'"); !errors.Is(err, hd.ErrUnsupportedCurve) {
+			t.Errorf("expected ErrUnsupportedCurve for %s, got %v", kt, err)
+		}
+	}
+	if _, err := hd.DeriveKey(key.ECDSA256, testSeed()[:8], "m/0'"); err == nil {
+		t.Error("expected error for short seed")
+	}
+	for _, path := range []string{"m/", "m//1", "m/x", "m/2147483648", "m/-1'"} {
+		if _, _, err := hd.ParsePath(path); err == nil {
+			t.Errorf("expected error parsing path %q", path)
+		}
+	}
+}
+
+// ---- Password derived keys ----
+
+// fastPasswordParams returns cheap parameters so tests stay quick, never use these outside tests.
+func fastPasswordParams(kdf key.PasswordKDF) key.PasswordParams {
+	if kdf == key.Scrypt {
+		return key.PasswordParams{KDF: key.Scrypt, N: 1 << 10, R: 8, P: 1}
+	}
+	return key.PasswordParams{KDF: key.Argon2id, Time: 1, Memory: 1024, Threads: 1}
+}
+
+func TestDeriveKeyFromPassword(t *testing.T) {
+	password := []byte("correct horse battery staple")
+	salt := []byte("0123456789abcdef")
+
+	for _, kdf := range []key.PasswordKDF{key.Argon2id, key.Scrypt} {
+		params := fastPasswordParams(kdf)
+
+		k, err := key.DeriveKeyFromPassword(key.ED25519, password, salt, params)
+		if err != nil {
+			t.Fatalf("DeriveKeyFromPassword(%d): %v", kdf, err)
+		}
+		k2, _ := key.DeriveKeyFromPassword(key.ED25519, password, salt, params)
+		if k.String() != k2.String() {
+			t.Errorf("kdf %d: same password and salt derived different keys", kdf)
+		}
+
+		k3, _ := key.DeriveKeyFromPassword(key.ED25519, password, []byte("fedcba9876543210"), params)
+		if k.String() == k3.String() {
+			t.Errorf("kdf %d: different salts derived the same key", kdf)
+		}
+		k4, _ := key.DeriveKeyFromPassword(key.ED25519, []byte("wrong password"), salt, params)
+		if k.String() == k4.String() {
+			t.Errorf("kdf %d: different passwords derived the same key", kdf)
+		}
+
+		signed, err := k.SignMessage([]byte("hello, world"))
+		if err != nil {
+			t.Fatalf("SignMessage: %v", err)
+		}
+		if !k2.VerifyMessage([]byte("hello, world"), signed) {
+			t.Errorf("kdf %d: re-derived key failed to verify signature", kdf)
+		}
+
+		a, err := key.DeriveKeyExchangeFromPassword(key.CURVE25519, password, salt, params)
+		if err != nil {
+			t.Fatalf("DeriveKeyExchangeFromPassword(%d): %v", kdf, err)
+		}
+		a2, _ := key.DeriveKeyExchangeFromPassword(key.CURVE25519, password, salt, params)
+		if a.String() != a2.String() {
+			t.Errorf("kdf %d: same password and salt derived different key exchanges", kdf)
+		}
+	}
+
+	// Argon2id and scrypt must not derive the same key
+	ka, _ := key.DeriveKeyFromPassword(key.ED25519, password, salt, fastPasswordParams(key.Argon2id))
+	ks, _ := key.DeriveKeyFromPassword(key.ED25519, password, salt, fastPasswordParams(key.Scrypt))
+	if ka.String() == ks.String() {
+		t.Error("Argon2id and scrypt derived the same key")
+	}
+}
+
+func TestDeriveKeyFromPasswordErrors(t *testing.T) {
+	password := []byte("correct horse battery staple")
+	salt := []byte("0123456789abcdef")
+	params := fastPasswordParams(key.Argon2id)
+
+	cases := []struct {
+		name     string
+		kt       shared.KeyType
+		password []byte
+		salt     []byte
+		params   key.PasswordParams
+	}{
+		{"empty password", key.ED25519, nil, salt, params},
+		{"short salt", key.ED25519, password, salt[:8], params},
+		{"unknown kdf", key.ED25519, password, salt, key.PasswordParams{}},
+		{"zero argon2id threads", key.ED25519, password, salt, key.PasswordParams{KDF: key.Argon2id, Time: 1, Memory: 1024}},
+		{"invalid scrypt N", key.ED25519, password, salt, key.PasswordParams{KDF: key.Scrypt, N: 1000, R: 8, P: 1}},
+		{"RSA", key.RSA2048, password, salt, params},
+	}
+	for _, c := range cases {
+		if _, err := key.DeriveKeyFromPassword(c.kt, c.password, c.salt, c.params); err == nil {
+			t.Errorf("%s: expected error", c.name)
+		}
+	}
+
+	if _, err := key.StretchPassword(nil, salt, params); err == nil || !strings.HasPrefix(err.Error(), "stretchpassword: ") {
+		t.Errorf("expected a stretchpassword error for an empty password, got %v", err)
+	}
+}
+
+// ---- Destroying key material ----
+
+func TestKeyDestroy(t *testing.T) {
+	for _, kt := range []shared.KeyType{key.ED25519, key.ECDSA256, key.ECDSA384, key.ECDSA521, key.RSA2048} {
+		k, err := key.GenerateKey(kt)
+		if err != nil {
+			t.Fatalf("GenerateKey(%s): %v", kt, err)
+		}
+		h := hashMsg(t)
+		if kt == key.ECDSA521 {
+			d := sha512.Sum512(h)
+			h = d[:]
+		}
+
+		var priv *big.Int
+		switch p := k.PrivateKeyInstance().(type) {
+		case *ecdsa.PrivateKey:
+			priv = p.D
+		}
+		edPriv, _ := k.PrivateKeyInstance().(ed25519.PrivateKey)
+
+		k.Destroy()
+
+		if _, err := k.Sign(h); !errors.Is(err, key.ErrKeyDestroyed) {
+			t.Errorf("%s: Sign after Destroy = %v, want ErrKeyDestroyed", kt, err)
+		}
+		if _, err := k.SignMessage([]byte("hello, world")); !errors.Is(err, key.ErrKeyDestroyed) {
+			t.Errorf("%s: SignMessage after Destroy = %v, want ErrKeyDestroyed", kt, err)
+		}
+		if _, err := k.Bytes(); !errors.Is(err, key.ErrKeyDestroyed) {
+			t.Errorf("%s: Bytes after Destroy = %v, want ErrKeyDestroyed", kt, err)
+		}
+		if k.IsPrivateKey() || k.IsPublicKey() {
+			t.Errorf("%s: destroyed key still reports key material", kt)
+		}
+		if k.PrivateKeyInstance() != nil {
+			t.Errorf("%s: destroyed key still returns a private key instance", kt)
+		}
+		if nil != priv && priv.Sign() != 0 {
+			t.Errorf("%s: private scalar was not zeroed", kt)
+		}
+		if nil != edPriv && !bytes.Equal(edPriv, make([]byte, len(edPriv))) {
+			t.Errorf("%s: private key was not zeroed", kt)
+		}
+	}
+}
+
+func TestKXDestroy(t *testing.T) {
+	for _, kxt := range []shared.KeyXType{key.CURVE25519, key.ECDH256, key.ECDH384, key.ECDH521} {
+		a, err := key.GenerateKeyExchange(kxt)
+		if err != nil {
+			t.Fatalf("GenerateKeyExchange(%s): %v", kxt, err)
+		}
+		b, _ := key.GenerateKeyExchange(kxt)
+
+		a.Destroy()
+
+		if _, err := a.SharedSecret(b.PublicKey()); !errors.Is(err, key.ErrKeyDestroyed) {
+			t.Errorf("%s: SharedSecret after Destroy = %v, want ErrKeyDestroyed", kxt, err)
+		}
+		if _, err := a.Bytes(); !errors.Is(err, key.ErrKeyDestroyed) {
+			t.Errorf("%s: Bytes after Destroy = %v, want ErrKeyDestroyed", kxt, err)
+		}
+		if a.IsPrivateKey() || a.IsPublicKey() {
+			t.Errorf("%s: destroyed key exchange still reports key material", kxt)
+		}
+		if _, err := b.SharedSecret(a.PublicKey()); err == nil {
+			t.Errorf("%s: expected error computing shared secret with a destroyed peer", kxt)
+		}
+	}
+}
+
+// ---- Key equality ----
+
+func TestKeyEqual(t *testing.T) {
+	for _, kt := range []shared.KeyType{key.ED25519, key.ECDSA256, key.ECDSA384, key.ECDSA521, key.RSA2048} {
+		k, err := key.GenerateKey(kt)
+		if err != nil {
+			t.Fatalf("GenerateKey(%s): %v", kt, err)
+		}
+		other, _ := key.GenerateKey(kt)
+		kPub, _ := k.PublicKey()
+
+		// the same key with a different key ID is still equal
+		k2, err := key.NewKeyFromStr(k.String())
+		if err != nil {
+			t.Fatalf("NewKeyFromStr: %v", err)
+		}
+		k2.(interface{ SetKeyID(string) error }).SetKeyID("another-kid")
+		if k.String() == k2.String() {
+			t.Fatalf("%s: expected different JWK output after changing the key ID", kt)
+		}
+
+		if !k.Equal(k2) {
+			t.Errorf("%s: key should equal its copy with a different key ID", kt)
+		}
+		if k.Equal(other) {
+			t.Errorf("%s: different keys should not be equal", kt)
+		}
+		if k.Equal(kPub) || kPub.Equal(k) {
+			t.Errorf("%s: private key should not equal its public key", kt)
+		}
+		if !k.PublicEqual(kPub) || !kPub.PublicEqual(k) {
+			t.Errorf("%s: private key should publicly equal its public key", kt)
+		}
+		if k.PublicEqual(other) {
+			t.Errorf("%s: different keys should not be publicly equal", kt)
+		}
+		if k.Equal(nil) || k.PublicEqual(nil) {
+			t.Errorf("%s: key should not equal nil", kt)
+		}
+	}
+
+	// keys of different types are never equal
+	a, _ := key.GenerateKey(key.ECDSA256)
+	b, _ := key.GenerateKey(key.ECDSA384)
+	if a.Equal(b) || a.PublicEqual(b) {
+		t.Error("keys of different types should not be equal")
+	}
+}
+
+func TestKXEqual(t *testing.T) {
+	for _, kxt := range []shared.KeyXType{key.CURVE25519, key.ECDH256, key.ECDH384, key.ECDH521} {
+		a, err := key.GenerateKeyExchange(kxt)
+		if err != nil {
+			t.Fatalf("GenerateKeyExchange(%s): %v", kxt, err)
+		}
+		b, _ := key.GenerateKeyExchange(kxt)
+		a2, err := key.NewKXFromStr(a.String())
+		if err != nil {
+			t.Fatalf("NewKXFromStr: %v", err)
+		}
+
+		if !a.Equal(a2) {
+			t.Errorf("%s: key exchange should equal its decoded copy", kxt)
+		}
+		if a.Equal(b) || a.PublicEqual(b) {
+			t.Errorf("%s: different key exchanges should not be equal", kxt)
+		}
+		if a.Equal(a.PublicKey()) {
+			t.Errorf("%s: private key exchange should not equal its public key", kxt)
+		}
+		if !a.PublicEqual(a.PublicKey()) || !a.PublicKey().PublicEqual(a2) {
+			t.Errorf("%s: private key exchange should publicly equal its public key", kxt)
+		}
+		if a.Equal(nil) || a.PublicEqual(nil) {
+			t.Errorf("%s: key exchange should not equal nil", kxt)
+		}
+	}
+}
+
+// ---- Validation and strict parsing ----
+
+// jwkMap decodes a JWK string into its members so tests can tamper with them.
+func jwkMap(t *testing.T, k key.Key) map[string]any {
+	t.Helper()
+	m := make(map[string]any)
+	if err := json.Unmarshal([]byte(k.String()), &m); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	return m
+}
+
+func jwkBytes(t *testing.T, m map[string]any) []byte {
+	t.Helper()
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatalf("json.Marshal: %v", err)
+	}
+	return b
+}
+
+func TestValidate(t *testing.T) {
+	for _, kt := range []shared.KeyType{key.ED25519, key.ECDSA256, key.ECDSA384, key.ECDSA521, key.RSA2048} {
+		k, err := key.GenerateKey(kt)
+		if err != nil {
+			t.Fatalf("GenerateKey(%s): %v", kt, err)
+		}
+		if err := k.Validate(); err != nil {
+			t.Errorf("%s: Validate private key: %v", kt, err)
+		}
+		kPub, _ := k.PublicKey()
+		if err := kPub.Validate(); err != nil {
+			t.Errorf("%s: Validate public key: %v", kt, err)
+		}
+		if _, err := key.NewKeyFromStr(k.String(), key.WithStrict()); err != nil {
+			t.Errorf("%s: strict parse of private key: %v", kt, err)
+		}
+		if _, err := key.NewKeyFromStr(kPub.String(), key.WithStrict()); err != nil {
+			t.Errorf("%s: strict parse of public key: %v", kt, err)
+		}
+	}
+
+	for _, kxt := range []shared.KeyXType{key.CURVE25519, key.ECDH256, key.ECDH384, key.ECDH521} {
+		kx, err := key.GenerateKeyExchange(kxt)
+		if err != nil {
+			t.Fatalf("GenerateKeyExchange(%s): %v", kxt, err)
+		}
+		if err := kx.Validate(); err != nil {
+			t.Errorf("%s: Validate private key exchange: %v", kxt, err)
+		}
+		if err := kx.PublicKey().Validate(); err != nil {
+			t.Errorf("%s: Validate public key exchange: %v", kxt, err)
+		}
+		if _, err := key.NewKXFromStr(kx.PublicKey().String(), key.WithStrict()); err != nil {
+			t.Errorf("%s: strict parse of public key exchange: %v", kxt, err)
+		}
+	}
+}
+
+func TestStrictMismatchedJWK(t *testing.T) {
+	for _, kt := range []shared.KeyType{key.ED25519, key.ECDSA256, key.RSA2048} {
+		k, _ := key.GenerateKey(kt)
+		other, _ := key.GenerateKey(kt)
+
+		// the public members of the private JWK belong to another key
+		m := jwkMap(t, k)
+		for member, v := range jwkMap(t, other) {
+			if member == "x" || member == "y" || member == "n" {
+				m[member] = v
+			}
+		}
+		_, err := key.NewKeyFromBytes(jwkBytes(t, m), key.WithStrict())
+		if kt == key.ED25519 {
+			// the JWK parser already rejects an ED25519 public key that does not match the seed
+			if err == nil {
+				t.Errorf("%s: expected error for strict parse of mismatched JWK", kt)
+			}
+			continue
+		}
+		if !errors.Is(err, key.ErrInvalidKey) {
+			t.Errorf("%s: strict parse of mismatched JWK = %v, want ErrInvalidKey", kt, err)
+		}
+	}
+}
+
+func TestStrictRSAInconsistentKey(t *testing.T) {
+	k, _ := key.GenerateKey(key.RSA2048)
+	other, _ := key.GenerateKey(key.RSA2048)
+
+	// the private exponent belongs to another key
+	m := jwkMap(t, k)
+	m["d"] = jwkMap(t, other)["d"]
+	b := jwkBytes(t, m)
+
+	k2, err := key.NewKeyFromBytes(b)
+	if err == nil {
+		if err := k2.Validate(); !errors.Is(err, key.ErrInvalidKey) {
+			t.Errorf("Validate of inconsistent RSA key = %v, want ErrInvalidKey", err)
+		}
+	}
+	if _, err := key.NewKeyFromBytes(b, key.WithStrict()); err == nil {
+		t.Error("expected error for strict parse of inconsistent RSA key")
+	}
+}
+
+func TestStrictED25519SmallOrder(t *testing.T) {
+	p := make([]byte, 32) // 2^255 - 19 in little endian, a non-canonical encoding of 0
+	for i := range p {
+		p[i] = 0xff
+	}
+	p[0], p[31] = 0xed, 0x7f
+	p3 := bytes.Clone(p) // 2^255 - 16, a non-canonical encoding of the point with y = 3
+	p3[0] = 0xf0
+
+	bad := map[string][]byte{
+		"identity":            append([]byte{1}, make([]byte, 31)...),
+		"order 4":             make([]byte, 32),
+		"order 2":             append([]byte{0xec}, append(bytes.Repeat([]byte{0xff}, 30), 0x7f)...),
+		"order 8":             mustHex(t, "c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac037a"),
+		"order 8 neg x":       mustHex(t, "c7176a703d4dd84fba3c0b760d10670f2a2053fa2c39ccc64ec7fd7792ac03fa"),
+		"order 8 neg y":       mustHex(t, "26e8958fc2b227b045c3f489f2ef98f0d5dfac05d3c63339b13802886d53fc05"),
+		"non-canonical":       p,
+		"non-canonical y = 3": p3,
+		"not on the curve":    append([]byte{2}, make([]byte, 31)...),
+	}
+	for name, x := range bad {
+		jwkStr := `{"crv":"Ed25519","kty":"OKP","x":"` + base64.RawURLEncoding.EncodeToString(x) + `"}`
+		if _, err := key.NewKeyFromStr(jwkStr); err != nil {
+			t.Fatalf("%s: default parse should accept the key: %v", name, err)
+		}
+		if _, err := key.NewKeyFromStr(jwkStr, key.WithStrict()); !errors.Is(err, key.ErrInvalidKey) {
+			t.Errorf("%s: strict parse = %v, want ErrInvalidKey", name, err)
+		}
+	}
+}
+
+// x25519LowOrder returns Curve25519 public keys of small order.
+func x25519LowOrder(t *testing.T) map[string][]byte {
+	return map[string][]byte{
+		"zero":    make([]byte, 32),
+		"one":     append([]byte{1}, make([]byte, 31)...),
+		"order 8": mustHex(t, "e0eb7a7c3b41b8ae1656e3faf19fc46ada098deb9c32b1fd866205165f49b800"),
+	}
+}
+
+func TestStrictCurve25519SmallOrder(t *testing.T) {
+	for name, pub := range x25519LowOrder(t) {
+		b := append([]byte{crv.TypeCrvPub}, pub...)
+		if _, err := key.NewKXFromBytes(b); err != nil {
+			t.Fatalf("%s: default parse should accept the key exchange: %v", name, err)
+		}
+		if _, err := key.NewKXFromBytes(b, key.WithStrict()); !errors.Is(err, key.ErrInvalidKey) {
+			t.Errorf("%s: strict parse = %v, want ErrInvalidKey", name, err)
+		}
+	}
+
+	if _, err := key.NewKXFromBytes(append([]byte{crv.TypeCrvPriv}, make([]byte, 32)...), key.WithStrict()); !errors.Is(err, key.ErrInvalidKey) {
+		t.Errorf("strict parse of all zero private key = %v, want ErrInvalidKey", err)
+	}
+	if _, err := key.NewKXFromBytes([]byte{0xff, 0x01}); err == nil {
+		t.Error("expected error for unknown key exchange identifier")
+	}
+}
+
+// ---- Malicious peer public keys ----
+
+// maliciousKX reports the key type of the embedded key exchange but hands out an attacker chosen public key.
+type maliciousKX struct {
+	shared.KeyExchange
+	pub []byte
+}
+
+func (m maliciousKX) PublicKeyInstance() []byte { return m.pub }
+
+func TestCurve25519SharedSecretRejectsSmallOrder(t *testing.T) {
+	a, _ := key.GenerateKeyExchange(key.CURVE25519)
+	for name, pub := range x25519LowOrder(t) {
+		peer, err := key.NewKXFromBytes(append([]byte{crv.TypeCrvPub}, pub...))
+		if err != nil {
+			t.Fatalf("%s: NewKXFromBytes: %v", name, err)
+		}
+		if _, err := a.SharedSecret(peer); !errors.Is(err, key.ErrInvalidKey) {
+			t.Errorf("%s: SharedSecret = %v, want ErrInvalidKey", name, err)
+		}
+	}
+
+	b, _ := key.GenerateKeyExchange(key.CURVE25519)
+	short := maliciousKX{KeyExchange: b.PublicKey(), pub: make([]byte, 31)}
+	if _, err := a.SharedSecret(short); !errors.Is(err, key.ErrInvalidKey) {
+		t.Errorf("short public key: SharedSecret = %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestSharedSecretPeerTypeMismatch(t *testing.T) {
+	c, _ := key.GenerateKeyExchange(key.CURVE25519)
+	e256, _ := key.GenerateKeyExchange(key.ECDH256)
+	e384, _ := key.GenerateKeyExchange(key.ECDH384)
+
+	// a 33 byte P-256 point must not be used as a Curve25519 key, nor the other way around
+	if _, err := c.SharedSecret(e256.PublicKey()); err == nil {
+		t.Error("expected error for CURVE25519 with an ECDH256 peer")
+	}
+	if _, err := e256.SharedSecret(c.PublicKey()); err == nil {
+		t.Error("expected error for ECDH256 with a CURVE25519 peer")
+	}
+	if _, err := e256.SharedSecret(e384.PublicKey()); err == nil {
+		t.Error("expected error for ECDH256 with an ECDH384 peer")
+	}
+	if _, err := c.SharedSecret(nil); err == nil {
+		t.Error("expected error for nil peer")
+	}
+}
+
+func TestECDHSharedSecretRejectsInvalidPoints(t *testing.T) {
+	for _, kxt := range []shared.KeyXType{key.ECDH256, key.ECDH384, key.ECDH521} {
+		a, _ := key.GenerateKeyExchange(kxt)
+		b, _ := key.GenerateKeyExchange(kxt)
+		pub := b.PublicKey().PublicKeyInstance()
+
+		offCurve := bytes.Clone(pub)
+		offCurve[len(offCurve)-1] ^= 1
+
+		bad := map[string][]byte{
+			"infinity":  {0},
+			"off curve": offCurve,
+			"truncated": pub[:len(pub)-1],
+			"zero":      make([]byte, len(pub)),
+		}
+		for name, p := range bad {
+			peer := maliciousKX{KeyExchange: b.PublicKey(), pub: p}
+			if _, err := a.SharedSecret(peer); !errors.Is(err, key.ErrInvalidKey) {
+				t.Errorf("%s %s: SharedSecret = %v, want ErrInvalidKey", kxt, name, err)
+			}
+		}
+	}
+}
+
+// ---- Key policy ----
+
+func TestPolicy(t *testing.T) {
+	policy := &key.Policy{Rules: []key.Rule{
+		// no new RSA2048 signing keys, existing ones may still be imported for verification
+		{Operations: []key.Operation{key.OpGenerate}, DenyKeyTypes: []shared.KeyType{key.RSA2048}},
+		// ECDH only on P-384 and larger
+		{AllowKeyXTypes: []shared.KeyXType{key.ECDH384, key.ECDH521}},
+		// ED25519 is blocked entirely
+		{DenyKeyTypes: []shared.KeyType{key.ED25519}},
+	}}
+	withPolicy := key.WithPolicy(policy)
+
+	_, err := key.GenerateKey(key.RSA2048, withPolicy)
+	var pe *key.PolicyError
+	if !errors.As(err, &pe) || !errors.Is(err, key.ErrPolicyViolation) {
+		t.Fatalf("GenerateKey(RSA2048) = %v, want PolicyError", err)
+	}
+	if pe.Operation != key.OpGenerate || pe.KeyType != key.RSA2048 {
+		t.Errorf("PolicyError = %+v, want generate of RSA2048", pe)
+	}
+
+	rsaKey, _ := key.GenerateKey(key.RSA2048)
+	if _, err := key.NewKeyFromStr(rsaKey.String(), withPolicy); err != nil {
+		t.Errorf("importing RSA2048 should be allowed: %v", err)
+	}
+
+	if _, err := key.GenerateKey(key.ECDSA384, withPolicy); err != nil {
+		t.Errorf("GenerateKey(ECDSA384) should be allowed: %v", err)
+	}
+
+	edKey, _ := key.GenerateKey(key.ED25519)
+	if _, err := key.GenerateKey(key.ED25519, withPolicy); !errors.Is(err, key.ErrPolicyViolation) {
+		t.Errorf("GenerateKey(ED25519) = %v, want ErrPolicyViolation", err)
+	}
+	if _, err := key.NewKeyFromStr(edKey.String(), withPolicy); !errors.Is(err, key.ErrPolicyViolation) {
+		t.Errorf("NewKeyFromStr(ED25519) = %v, want ErrPolicyViolation", err)
+	}
+
+	for _, kxt := range []shared.KeyXType{key.CURVE25519, key.ECDH256} {
+		if _, err := key.GenerateKeyExchange(kxt, withPolicy); !errors.Is(err, key.ErrPolicyViolation) {
+			t.Errorf("GenerateKeyExchange(%s) = %v, want ErrPolicyViolation", kxt, err)
+		}
+		kx, _ := key.GenerateKeyExchange(kxt)
+		_, err := key.NewKXFromStr(kx.String(), withPolicy)
+		if !errors.As(err, &pe) || pe.KeyXType != kxt || pe.Operation != key.OpImport {
+			t.Errorf("NewKXFromStr(%s) = %v, want PolicyError for import", kxt, err)
+		}
+	}
+	if _, err := key.GenerateKeyExchange(key.ECDH384, withPolicy); err != nil {
+		t.Errorf("GenerateKeyExchange(ECDH384) should be allowed: %v", err)
+	}
+}
+
+func TestPolicyMinBits(t *testing.T) {
+	policy := &key.Policy{Rules: []key.Rule{{MinKeyBits: 384, MinKeyXBits: 384}}}
+
+	if err := policy.CheckKey(key.ECDSA256, key.OpGenerate); !errors.Is(err, key.ErrPolicyViolation) {
+		t.Errorf("CheckKey(ECDSA256) = %v, want ErrPolicyViolation", err)
+	}
+	if err := policy.CheckKey(key.ECDSA521, key.OpImport); err != nil {
+		t.Errorf("CheckKey(ECDSA521) = %v, want nil", err)
+	}
+	if err := policy.CheckKeyX(key.ECDH256, key.OpImport); !errors.Is(err, key.ErrPolicyViolation) {
+		t.Errorf("CheckKeyX(ECDH256) = %v, want ErrPolicyViolation", err)
+	}
+
+	// a nil policy allows everything
+	var none *key.Policy
+	if err := none.CheckKey(key.RSA2048, key.OpGenerate); err != nil {
+		t.Errorf("nil policy CheckKey = %v, want nil", err)
+	}
+}
+
+// ---- FIPS 140-3 mode ----
+
+func TestFIPSModeDisabled(t *testing.T) {
+	if key.FIPSMode() {
+		t.Skip("running in FIPS 140-3 mode")
+	}
+	if got := len(key.AvailableKeyTypes()); got != 7 {
+		t.Errorf("AvailableKeyTypes returned %d types, want 7", got)
+	}
+	if got := len(key.AvailableKeyXTypes()); got != 4 {
+		t.Errorf("AvailableKeyXTypes returned %d types, want 4", got)
+	}
+}
+
+// TestFIPSMode runs TestFIPSModeEnabled in a new process since FIPS 140-3 mode can only be selected at startup.
+func TestFIPSMode(t *testing.T) {
+	if key.FIPSMode() {
+		t.Skip("already running in FIPS 140-3 mode")
+	}
+
+	// `only` also makes non-approved primitives fail, so approved operations must not depend on them
+	for _, mode := range []string{"on", "only"} {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestFIPSModeEnabled$", "-test.v")
+		cmd.Env = append(os.Environ(), "GODEBUG=fips140="+mode, "KEY_TEST_FIPS=1")
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("FIPS 140-3 mode %s tests failed: %v\n%s", mode, err, out)
+		}
+		if !strings.Contains(string(out), "--- PASS: TestFIPSModeEnabled") {
+			t.Fatalf("FIPS 140-3 mode %s tests did not run:\n%s", mode, out)
+		}
+	}
+}
+
+func TestFIPSModeEnabled(t *testing.T) {
+	if os.Getenv("KEY_TEST_FIPS") != "1" {
+		t.Skip("run by TestFIPSMode")
+	}
+	if !key.FIPSMode() {
+		t.Fatal("FIPSMode should be enabled with GODEBUG=fips140=on")
+	}
+
+	if got := len(key.AvailableKeyTypes()); got != 7 {
+		t.Errorf("AvailableKeyTypes returned %d types, want 7", got)
+	}
+	for _, kxt := range key.AvailableKeyXTypes() {
+		if kxt == key.CURVE25519 {
+			t.Error("CURVE25519 should not be available in FIPS 140-3 mode")
+		}
+	}
+
+	// approved algorithms keep working
+	k, err := key.GenerateKey(key.ECDSA256)
+	if err != nil {
+		t.Fatalf("GenerateKey(ECDSA256): %v", err)
+	}
+	signed, err := k.SignMessage([]byte("hello, world"))
+	if err != nil || !k.VerifyMessage([]byte("hello, world"), signed) {
+		t.Errorf("ECDSA256 sign/verify failed: %v", err)
+	}
+	ek, _ := key.GenerateKey(key.ED25519)
+	if err := ek.Validate(); err != nil {
+		t.Errorf("ED25519 Validate: %v", err)
+	}
+	a, err := key.GenerateKeyExchange(key.ECDH384)
+	if err != nil {
+		t.Fatalf("GenerateKeyExchange(ECDH384): %v", err)
+	}
+	b, _ := key.GenerateKeyExchange(key.ECDH384)
+	if _, err := a.SharedSecret(b.PublicKey()); err != nil {
+		t.Errorf("ECDH384 SharedSecret: %v", err)
+	}
+	ciphertext, err := key.Encrypt(a, b.PublicKey(), []byte("hello, world"), nil, key.AES256GCM)
+	if err != nil {
+		t.Fatalf("Encrypt(AES256GCM): %v", err)
+	}
+	if plaintext, err := key.Decrypt(b, a.PublicKey(), ciphertext, nil); err != nil || string(plaintext) != "hello, world" {
+		t.Errorf("Decrypt(AES256GCM) = %q, %v", plaintext, err)
+	}
+	hs, err := key.GenerateSymmetricKey(key.HS256)
+	if err != nil {
+		t.Fatalf("GenerateSymmetricKey(HS256): %v", err)
+	}
+	if signed, err := hs.SignMessage([]byte("hello, world")); err != nil || !hs.VerifyMessage([]byte("hello, world"), signed) {
+		t.Errorf("HS256 sign/verify failed: %v", err)
+	}
+	gk, err := key.GenerateSymmetricKey(key.A256GCM)
+	if err != nil {
+		t.Fatalf("GenerateSymmetricKey(A256GCM): %v", err)
+	}
+
+	// everything else fails with an explicit error
+	seed := testSeed()
+	salt := []byte("0123456789abcdef")
+	denied := map[string]error{}
+	_, denied["GenerateKeyExchange(CURVE25519)"] = key.GenerateKeyExchange(key.CURVE25519)
+	_, denied["NewKXFromBytes(CURVE25519)"] = key.NewKXFromBytes(append([]byte{crv.TypeCrvPub}, make([]byte, 32)...))
+	_, denied["GenerateKey with WithRand"] = key.GenerateKey(key.ECDSA256, key.WithRand(seededRand()))
+	_, denied["ECDSA256 Sign with WithRand"] = k.SignMessage([]byte("hello, world"), key.WithRand(seededRand()))
+	rk, err := key.GenerateKey(key.RSA2048)
+	if err != nil {
+		t.Fatalf("GenerateKey(RSA2048): %v", err)
+	}
+	_, denied["RSA2048 Sign with WithRand"] = rk.SignMessage([]byte("hello, world"), key.WithRand(seededRand()))
+	_, denied["GenerateKeyFromSeed"] = key.GenerateKeyFromSeed(key.ED25519, seed, "tenant-1")
+	_, denied["DeriveKeyFromPassword"] = key.DeriveKeyFromPassword(key.ED25519, []byte("password"), salt, fastPasswordParams(key.Argon2id))
+	_, denied["hd.NewMaster"] = hd.NewMaster(key.ED25519, seed)
+	_, denied["Encrypt(XChaCha20Poly1305)"] = key.Encrypt(a, b.PublicKey(), []byte("hello, world"), nil, key.XChaCha20Poly1305)
+	_, denied["A256GCM AEAD"] = gk.AEAD()
+	for name, err := range denied {
+		if !errors.Is(err, key.ErrNotFIPSApproved) {
+			t.Errorf("%s = %v, want ErrNotFIPSApproved", name, err)
+		}
+	}
+}
+
+// ---- Validity windows and usage limits ----
+
+func TestKeyValidityWindow(t *testing.T) {
+	now := time.Now()
+
+	for _, kt := range []shared.KeyType{key.ED25519, key.ECDSA256, key.RSA2048} {
+		k, _ := key.GenerateKey(kt)
+
+		k.SetValidity(key.Validity{NotBefore: now.Add(time.Hour)})
+		_, err := k.SignMessage([]byte("hello, world"))
+		var ve *key.ValidityError
+		if !errors.As(err, &ve) || !errors.Is(err, key.ErrKeyNotYetValid) {
+			t.Errorf("%s: Sign before nbf = %v, want ErrKeyNotYetValid", kt, err)
+		}
+
+		k.SetValidity(key.Validity{NotBefore: now.Add(-time.Hour), NotAfter: now.Add(-time.Minute)})
+		if _, err := k.Sign(hashMsg(t)); !errors.Is(err, key.ErrKeyExpired) {
+			t.Errorf("%s: Sign after exp = %v, want ErrKeyExpired", kt, err)
+		}
+
+		k.SetValidity(key.Validity{NotBefore: now.Add(-time.Hour), NotAfter: now.Add(time.Hour)})
+		signed, err := k.SignMessage([]byte("hello, world"))
+		if err != nil {
+			t.Fatalf("%s: Sign within window: %v", kt, err)
+		}
+
+		// verification is not restricted by the window
+		k.SetValidity(key.Validity{NotAfter: now.Add(-time.Minute)})
+		if !k.VerifyMessage([]byte("hello, world"), signed) {
+			t.Errorf("%s: Verify should not be restricted by the validity window", kt)
+		}
+	}
+}
+
+func TestKeyValidityJWK(t *testing.T) {
+	nbf := time.Unix(1700000000, 0)
+	exp := time.Unix(1800000000, 0)
+
+	for _, kt := range []shared.KeyType{key.ED25519, key.ECDSA256, key.RSA2048} {
+		k, _ := key.GenerateKey(kt)
+		k.SetKeyID("signing-2024-q1")
+		k.SetValidity(key.Validity{NotBefore: nbf, NotAfter: exp, MaxUses: 10})
+
+		m := jwkMap(t, k)
+		if m["nbf"] != float64(nbf.Unix()) || m["exp"] != float64(exp.Unix()) {
+			t.Errorf("%s: JWK nbf/exp = %v/%v, want %d/%d", kt, m["nbf"], m["exp"], nbf.Unix(), exp.Unix())
+		}
+		if _, ok := m["max_uses"]; ok {
+			t.Errorf("%s: maximum uses should not be written to the JWK", kt)
+		}
+
+		// the public key carries the same key ID and window for verifiers
+		kPub, _ := k.PublicKey()
+		if kPub.GetKeyID() != "signing-2024-q1" || !kPub.Validity().NotAfter.Equal(exp) {
+			t.Errorf("%s: public key lost key ID or validity: %s", kt, kPub)
+		}
+
+		k2, err := key.NewKeyFromStr(k.String())
+		if err != nil {
+			t.Fatalf("%s: NewKeyFromStr: %v", kt, err)
+		}
+		if k2.GetKeyID() != "signing-2024-q1" {
+			t.Errorf("%s: parsed key ID = %q, want signing-2024-q1", kt, k2.GetKeyID())
+		}
+		v := k2.Validity()
+		if !v.NotBefore.Equal(nbf) || !v.NotAfter.Equal(exp) || v.MaxUses != 0 {
+			t.Errorf("%s: parsed validity = %+v", kt, v)
+		}
+		if k2.String() != k.String() {
+			t.Errorf("%s: JWK changed after round trip:\n%s\n%s", kt, k, k2)
+		}
+
+		// a parsed key checks its window against the given clock, like a generated key
+		k3, err := key.NewKeyFromStr(k.String(), key.WithClock(func() time.Time { return exp }))
+		if err != nil {
+			t.Fatalf("%s: NewKeyFromStr with clock: %v", kt, err)
+		}
+		if _, err := k3.SignMessage([]byte("hello, world")); !errors.Is(err, key.ErrKeyExpired) {
+			t.Errorf("%s: SignMessage of a parsed key after its expiry = %v, want ErrKeyExpired", kt, err)
+		}
+	}
+
+	hs, _ := key.GenerateSymmetricKey(key.HS256)
+	hs.SetValidity(key.Validity{NotBefore: nbf, NotAfter: exp})
+	hs2, err := key.NewSymmetricKeyFromStr(hs.String(), key.HS256, key.WithClock(func() time.Time { return nbf.Add(-time.Second) }))
+	if err != nil {
+		t.Fatalf("NewSymmetricKeyFromStr with clock: %v", err)
+	}
+	if _, err := hs2.SignMessage([]byte("hello, world")); !errors.Is(err, key.ErrKeyNotYetValid) {
+		t.Errorf("SignMessage of a parsed symmetric key before its window = %v, want ErrKeyNotYetValid", err)
+	}
+}
+
+func TestKeyMaxUses(t *testing.T) {
+	k, _ := key.GenerateKey(key.ED25519)
+	k.SetValidity(key.Validity{MaxUses: 3})
+
+	for i := range 3 {
+		if _, err := k.SignMessage([]byte("hello, world")); err != nil {
+			t.Fatalf("Sign %d: %v", i+1, err)
+		}
+	}
+	_, err := k.SignMessage([]byte("hello, world"))
+	var ve *key.ValidityError
+	if !errors.As(err, &ve) || !errors.Is(err, key.ErrKeyUsageExceeded) || ve.Uses != 3 {
+		t.Errorf("Sign after maximum uses = %v, want ErrKeyUsageExceeded after 3 uses", err)
+	}
+	if k.Uses() != 3 {
+		t.Errorf("Uses = %d, want 3", k.Uses())
+	}
+}
+
+func TestKeyFailedSignNotCounted(t *testing.T) {
+	k, _ := key.GenerateKey(key.ECDSA256)
+	k.SetValidity(key.Validity{MaxUses: 1})
+
+	// deterministic signing fails for a digest of the wrong length after the use was reserved
+	k.(*ec.K).SetDeterministic(true)
+	if _, err := k.Sign(make([]byte, 40)); err == nil {
+		t.Fatal("Sign of a 40 byte digest should fail")
+	}
+	if k.Uses() != 0 {
+		t.Errorf("Uses after a failed signature = %d, want 0", k.Uses())
+	}
+	if _, err := k.SignMessage([]byte("hello, world")); err != nil {
+		t.Errorf("Sign after a failed signature: %v", err)
+	}
+
+	// the usage counter and JWK writer are internal to the key
+	keys := []any{}
+	for _, kt := range []shared.KeyType{key.ED25519, key.ECDSA256, key.RSA2048} {
+		k, _ := key.GenerateKey(kt)
+		keys = append(keys, k)
+	}
+	hs, _ := key.GenerateSymmetricKey(key.HS256)
+	for _, k := range append(keys, hs) {
+		if _, ok := k.(interface{ Use() error }); ok {
+			t.Errorf("%T: keys should not expose Use", k)
+		}
+		if _, ok := k.(interface {
+			WriteJWK(func(string, any) error) error
+		}); ok {
+			t.Errorf("%T: keys should not expose WriteJWK", k)
+		}
+	}
+}
+
+func TestKeyExpiryWarning(t *testing.T) {
+	k, _ := key.GenerateKey(key.ECDSA256)
+	exp := time.Now().Add(time.Hour)
+	k.SetValidity(key.Validity{NotAfter: exp})
+
+	var warned []time.Time
+	k.SetExpiryWarning(24*time.Hour, func(notAfter time.Time) {
+		warned = append(warned, notAfter)
+	})
+
+	if _, err := k.SignMessage([]byte("hello, world")); err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+	if len(warned) != 1 || !warned[0].Equal(exp) {
+		t.Errorf("expiry warnings = %v, want one for %s", warned, exp)
+	}
+
+	k.SetExpiryWarning(time.Minute, func(notAfter time.Time) {
+		t.Error("no warning expected outside the warning period")
+	})
+	if _, err := k.SignMessage([]byte("hello, world")); err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+}
+
+// ---- Key rotation ----
+
+// fakeClock is a manually advanced clock for rotation tests.
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// jwksKeyIDs returns the key IDs listed in a JWKS.
+func jwksKeyIDs(t *testing.T, m *rotation.Manager) []string {
+	t.Helper()
+	b, err := m.JWKS()
+	if err != nil {
+		t.Fatalf("JWKS: %v", err)
+	}
+	var set struct {
+		Keys []struct {
+			KeyID string `json:"kid"`
+			D     string `json:"d"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(b, &set); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	var kids []string
+	for _, k := range set.Keys {
+		if k.D != "" {
+			t.Errorf("JWKS contains private key %s", k.KeyID)
+		}
+		kids = append(kids, k.KeyID)
+	}
+	return kids
+}
+
+func TestRotationManager(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	m, err := rotation.New(rotation.Config{
+		KeyType:     key.ED25519,
+		Interval:    90 * 24 * time.Hour,
+		GracePeriod: 7 * 24 * time.Hour,
+		Now:         clock.Now,
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	first, next := m.Active(), m.Next()
+	if first.Equal(next) {
+		t.Fatal("active and next key should differ")
+	}
+
+	// the next key is published before it is used
+	if kids := jwksKeyIDs(t, m); len(kids) != 2 || kids[0] != next.GetKeyID() || kids[1] != first.GetKeyID() {
+		t.Errorf("JWKS key IDs = %v, want next and active", kids)
+	}
+
+	kid, signed, err := m.SignMessage([]byte("hello, world"))
+	if err != nil {
+		t.Fatalf("SignMessage: %v", err)
+	}
+	if kid != first.GetKeyID() {
+		t.Errorf("signed with %s, want active key %s", kid, first.GetKeyID())
+	}
+
+	// not due yet
+	clock.Advance(89 * 24 * time.Hour)
+	if rotated, err := m.RotateIfDue(); err != nil || rotated {
+		t.Fatalf("RotateIfDue before interval = %v, %v", rotated, err)
+	}
+
+	clock.Advance(24 * time.Hour)
+	if rotated, err := m.RotateIfDue(); err != nil || !rotated {
+		t.Fatalf("RotateIfDue after interval = %v, %v", rotated, err)
+	}
+	if !m.Active().Equal(next) {
+		t.Error("next key should be active after rotation")
+	}
+	if prev := m.Previous(); len(prev) != 1 || !prev[0].Equal(first) {
+		t.Errorf("Previous = %v, want the first key", prev)
+	}
+
+	// the retired key cannot sign but still verifies during the grace period
+	if _, err := first.SignMessage([]byte("hello, world")); !errors.Is(err, key.ErrKeyExpired) {
+		t.Errorf("retired key Sign = %v, want ErrKeyExpired", err)
+	}
+	v, err := m.Verifier(kid)
+	if err != nil {
+		t.Fatalf("Verifier(%s): %v", kid, err)
+	}
+	if v.IsPrivateKey() || !v.VerifyMessage([]byte("hello, world"), signed) {
+		t.Error("verifier for previous key should be a public key that verifies its signatures")
+	}
+	if len(jwksKeyIDs(t, m)) != 3 {
+		t.Error("JWKS should list next, active and previous keys during the grace period")
+	}
+
+	// verifiers are told the retired key expires at the end of the grace period, not when it stopped signing
+	graceEnd := clock.Now().Add(7 * 24 * time.Hour)
+	if !v.Validity().NotAfter.Equal(graceEnd) {
+		t.Errorf("Verifier exp = %s, want the end of the grace period %s", v.Validity().NotAfter, graceEnd)
+	}
+	jwks, err := m.JWKS()
+	if err != nil {
+		t.Fatalf("JWKS: %v", err)
+	}
+	var set struct {
+		Keys []struct {
+			KeyID     string `json:"kid"`
+			ExpiresAt int64  `json:"exp"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(jwks, &set); err != nil {
+		t.Fatalf("json.Unmarshal: %v", err)
+	}
+	for _, k := range set.Keys {
+		if k.KeyID == kid && k.ExpiresAt != graceEnd.Unix() {
+			t.Errorf("JWKS exp of the retired key = %d, want %d", k.ExpiresAt, graceEnd.Unix())
+		}
+	}
+
+	clock.Advance(7 * 24 * time.Hour)
+	if _, err := m.Verifier(kid); !errors.Is(err, rotation.ErrUnknownKeyID) {
+		t.Errorf("Verifier after grace period = %v, want ErrUnknownKeyID", err)
+	}
+	if _, err := m.RotateIfDue(); err != nil {
+		t.Fatalf("RotateIfDue: %v", err)
+	}
+	if len(m.Previous()) != 0 || len(jwksKeyIDs(t, m)) != 2 {
+		t.Error("previous key should be dropped after the grace period")
+	}
+
+	// dropping a key does not destroy it for callers still holding it
+	if !first.IsPrivateKey() || !first.VerifyMessage([]byte("hello, world"), signed) {
+		t.Error("dropped key should still be usable by callers holding it")
+	}
+}
+
+func TestRotationClock(t *testing.T) {
+	// a clock ahead of the wall clock, the retired key must stop signing by the manager's clock
+	clock := &fakeClock{now: time.Now().Add(365 * 24 * time.Hour)}
+	m, err := rotation.New(rotation.Config{KeyType: key.ED25519, GracePeriod: time.Hour, Now: clock.Now})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	first := m.Active()
+	if err := m.Rotate(); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if _, err := first.SignMessage([]byte("hello, world")); !errors.Is(err, key.ErrKeyExpired) {
+		t.Errorf("retired key Sign = %v, want ErrKeyExpired", err)
+	}
+	if _, _, err := m.SignMessage([]byte("hello, world")); err != nil {
+		t.Errorf("SignMessage with the active key: %v", err)
+	}
+
+	// keys generated with a clock check their validity against it
+	k, _ := key.GenerateKey(key.ECDSA256, key.WithClock(clock.Now))
+	k.SetValidity(key.Validity{NotBefore: clock.Now().Add(-time.Minute)})
+	if _, err := k.SignMessage([]byte("hello, world")); err != nil {
+		t.Errorf("Sign within the window of the clock: %v", err)
+	}
+}
+
+func TestRotationOnDemand(t *testing.T) {
+	m, err := rotation.New(rotation.Config{KeyType: key.ECDSA256, GracePeriod: time.Hour})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	// without an interval keys only rotate on demand
+	if rotated, _ := m.RotateIfDue(); rotated {
+		t.Error("RotateIfDue should not rotate without an interval")
+	}
+
+	kids := map[string]bool{m.Active().GetKeyID(): true}
+	for range 3 {
+		if err := m.Rotate(); err != nil {
+			t.Fatalf("Rotate: %v", err)
+		}
+		kid, signed, err := m.SignMessage([]byte("hello, world"))
+		if err != nil {
+			t.Fatalf("SignMessage: %v", err)
+		}
+		if kids[kid] {
+			t.Errorf("key ID %s reused after rotation", kid)
+		}
+		kids[kid] = true
+
+		v, err := m.Verifier(kid)
+		if err != nil || !v.VerifyMessage([]byte("hello, world"), signed) {
+			t.Errorf("Verifier(%s) failed: %v", kid, err)
+		}
+	}
+	if len(m.Previous()) != 3 {
+		t.Errorf("Previous returned %d keys, want 3", len(m.Previous()))
+	}
+
+	if _, err := m.Verifier("unknown"); !errors.Is(err, rotation.ErrUnknownKeyID) {
+		t.Errorf("Verifier(unknown) = %v, want ErrUnknownKeyID", err)
+	}
+
+	for _, check := range []time.Duration{0, -time.Second} {
+		if err := m.Run(context.Background(), check); err == nil || errors.Is(err, context.Canceled) {
+			t.Errorf("Run(%s) = %v, want an error for the check interval", check, err)
+		}
+	}
+
+	// policies are applied when keys are generated
+	policy := &key.Policy{Rules: []key.Rule{{DenyKeyTypes: []shared.KeyType{key.ECDSA256}}}}
+	if _, err := rotation.New(rotation.Config{KeyType: key.ECDSA256, Options: []key.Option{key.WithPolicy(policy)}}); !errors.Is(err, key.ErrPolicyViolation) {
+		t.Errorf("New with policy = %v, want ErrPolicyViolation", err)
+	}
+}
+
+// ---- Encrypted file keystore ----
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir() + "/keys"
+	passphrase := []byte("correct horse battery staple")
+
+	s, err := keystore.NewFileStore(dir, passphrase, fastPasswordParams(key.Argon2id))
+	if err != nil {
+		t.Fatalf("NewFileStore: %v", err)
+	}
+
+	k, err := key.GenerateKey(key.ECDSA256)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	kid, err := s.Put(keystore.Entry{Key: k})
+	if err != nil {
+		t.Fatalf("Put key: %v", err)
+	}
+	if kid == "" {
+		t.Fatal("Put should default to the key's thumbprint")
+	}
+
+	kx, err := key.GenerateKeyExchange(key.CURVE25519)
+	if err != nil {
+		t.Fatalf("GenerateKeyExchange: %v", err)
+	}
+	kxid, err := s.Put(keystore.Entry{KeyExchange: kx})
+	if err != nil {
+		t.Fatalf("Put key exchange: %v", err)
+	}
+	if kxid != keystore.KeyExchangeID(kx) {
+		t.Errorf("key exchange stored as %s, want %s", kxid, keystore.KeyExchangeID(kx))
+	}
+
+	if _, err := s.Put(keystore.Entry{KID: "both", Key: k, KeyExchange: kx}); err == nil {
+		t.Error("Put should refuse an entry with both a key and a key exchange")
+	}
+
+	// the key material is encrypted at rest
+	des, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	kb, _ := k.Bytes()
+	var d struct {
+		D string `json:"d"`
+	}
+	json.Unmarshal(kb, &d)
+	for _, de := range des {
+		b, _ := os.ReadFile(dir + "/" + de.Name())
+		if bytes.Contains(b, []byte(d.D)) {
+			t.Errorf("%s contains the private key in plain text", de.Name())
+		}
+		if fi, _ := de.Info(); fi.Mode().Perm() != 0o600 {
+			t.Errorf("%s has permissions %#o, want 0600", de.Name(), fi.Mode().Perm())
+		}
+	}
+
+	infos, err := s.List()
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("List returned %d entries, want 2", len(infos))
+	}
+	for _, info := range infos {
+		switch info.KID {
+		case kid:
+			if info.Kind != keystore.KindKey || info.KeyType != key.ECDSA256 {
+				t.Errorf("key listed as %s %s", info.Kind, info.KeyType)
+			}
+		case kxid:
+			if info.Kind != keystore.KindKeyExchange || info.KeyXType != key.CURVE25519 {
+				t.Errorf("key exchange listed as %s %s", info.Kind, info.KeyXType)
+			}
+		default:
+			t.Errorf("unexpected entry %s", info.KID)
+		}
+	}
+
+	// reopening with the passphrase restores the keys
+	s, err = keystore.NewFileStore(dir, passphrase, key.DefaultArgon2idParams())
+	if err != nil {
+		t.Fatalf("reopen NewFileStore: %v", err)
+	}
+	e, err := s.Get(kid)
+	if err != nil {
+		t.Fatalf("Get key: %v", err)
+	}
+	if !e.Key.Equal(k) || e.Key.GetKeyID() != kid {
+		t.Error("stored key does not match")
+	}
+	if e, err = s.Get(kxid); err != nil || !e.KeyExchange.Equal(kx) {
+		t.Errorf("stored key exchange does not match: %v", err)
+	}
+
+	if _, err := keystore.NewFileStore(dir, []byte("wrong"), fastPasswordParams(key.Argon2id)); !errors.Is(err, keystore.ErrWrongKey) {
+		t.Errorf("NewFileStore with wrong passphrase = %v, want ErrWrongKey", err)
+	}
+	if _, err := keystore.NewFileStoreWithKey(dir, make([]byte, keystore.KEKSize)); err == nil {
+		t.Error("a passphrase keystore should not open with a wrapping key")
+	}
+
+	if err := s.Delete(kid); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get(kid); !errors.Is(err, keystore.ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(kid); !errors.Is(err, keystore.ErrNotFound) {
+		t.Errorf("Delete twice = %v, want ErrNotFound", err)
+	}
+}
+
+func TestFileStoreWithKey(t *testing.T) {
+	dir := t.TempDir() + "/keys"
+	kek := bytes.Repeat([]byte{0x42}, keystore.KEKSize)
+
+	s, err := keystore.NewFileStoreWithKey(dir, kek)
+	if err != nil {
+		t.Fatalf("NewFileStoreWithKey: %v", err)
+	}
+	k, _ := key.GenerateKey(key.ED25519)
+	if _, err := s.Put(keystore.Entry{KID: "signing/../../escape", Key: k}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	// key IDs are encoded so they cannot name a file outside the keystore
+	if _, err := os.Stat(dir + "/../escape.entry"); err == nil {
+		t.Error("key ID escaped the keystore directory")
+	}
+
+	if _, err := keystore.NewFileStoreWithKey(dir, bytes.Repeat([]byte{0x43}, keystore.KEKSize)); !errors.Is(err, keystore.ErrWrongKey) {
+		t.Errorf("NewFileStoreWithKey with wrong key = %v, want ErrWrongKey", err)
+	}
+	if _, err := keystore.NewFileStoreWithKey(dir, kek[:16]); err == nil {
+		t.Error("NewFileStoreWithKey should refuse a short wrapping key")
+	}
+
+	s, err = keystore.NewFileStoreWithKey(dir, kek)
+	if err != nil {
+		t.Fatalf("reopen NewFileStoreWithKey: %v", err)
+	}
+	if e, err := s.Get("signing/../../escape"); err != nil || !e.Key.Equal(k) {
+		t.Errorf("Get = %v", err)
+	}
+}
+
+func TestFileStorePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file permissions are not checked on Windows")
+	}
+
+	dir := t.TempDir()
+	if err := os.Chmod(dir, 0o755); err != nil {
+		t.Fatalf("Chmod: %v", err)
+	}
+	kek := make([]byte, keystore.KEKSize)
+	if _, err := keystore.NewFileStoreWithKey(dir, kek); !errors.Is(err, keystore.ErrInsecurePermissions) {
+		t.Errorf("NewFileStoreWithKey on a shared directory = %v, want ErrInsecurePermissions", err)
+	}
+
+	os.Chmod(dir, 0o700)
+	s, err := keystore.NewFileStoreWithKey(dir, kek)
+	if err != nil {
+		t.Fatalf("NewFileStoreWithKey: %v", err)
+	}
+	k, _ := key.GenerateKey(key.ED25519)
+	kid, err := s.Put(keystore.Entry{Key: k})
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	des, _ := os.ReadDir(dir)
+	for _, de := range des {
+		os.Chmod(dir+"/"+de.Name(), 0o644)
+	}
+	if _, err := s.Get(kid); !errors.Is(err, keystore.ErrInsecurePermissions) {
+		t.Errorf("Get of a world readable entry = %v, want ErrInsecurePermissions", err)
+	}
+	if _, err := keystore.NewFileStoreWithKey(dir, kek); !errors.Is(err, keystore.ErrInsecurePermissions) {
+		t.Errorf("NewFileStoreWithKey with world readable metadata = %v, want ErrInsecurePermissions", err)
+	}
+}
+
+func TestFileStoreTampering(t *testing.T) {
+	dir := t.TempDir() + "/keys"
+	s, err := keystore.NewFileStoreWithKey(dir, make([]byte, keystore.KEKSize))
+	if err != nil {
+		t.Fatalf("NewFileStoreWithKey: %v", err)
+	}
+
+	k1, _ := key.GenerateKey(key.ED25519)
+	k2, _ := key.GenerateKey(key.ED25519)
+	if _, err := s.Put(keystore.Entry{KID: "one", Key: k1}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := s.Put(keystore.Entry{KID: "two", Key: k2}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	one := dir + "/" + base64.RawURLEncoding.EncodeToString([]byte("one")) + ".entry"
+	two := dir + "/" + base64.RawURLEncoding.EncodeToString([]byte("two")) + ".entry"
+
+	// an entry moved to another key ID is detected
+	if err := os.Rename(two, one); err != nil {
+		t.Fatalf("Rename: %v", err)
+	}
+	if _, err := s.Get("one"); err == nil {
+		t.Error("Get should refuse an entry stored under another key ID")
+	}
+
+	// the unencrypted details are authenticated
+	b, _ := os.ReadFile(one)
+	b = bytes.Replace(b, []byte(`"kid":"two"`), []byte(`"kid":"one"`), 1)
+	os.WriteFile(one, b, 0o600)
+	if _, err := s.Get("one"); err == nil {
+		t.Error("Get should refuse an entry whose key ID was changed")
+	}
+}
+
+func TestFileStoreConcurrent(t *testing.T) {
+	s, err := keystore.NewFileStoreWithKey(t.TempDir()+"/keys", make([]byte, keystore.KEKSize))
+	if err != nil {
+		t.Fatalf("NewFileStoreWithKey: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			k, _ := key.GenerateKey(key.ED25519)
+			kid := fmt.Sprintf("key-%d", i)
+			for range 5 {
+				if _, err := s.Put(keystore.Entry{KID: kid, Key: k}); err != nil {
+					t.Errorf("Put: %v", err)
+					return
+				}
+				if e, err := s.Get(kid); err != nil || !e.Key.Equal(k) {
+					t.Errorf("Get(%s): %v", kid, err)
+					return
+				}
+				if _, err := s.List(); err != nil {
+					t.Errorf("List: %v", err)
+					return
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	if infos, _ := s.List(); len(infos) != 8 {
+		t.Errorf("List returned %d entries, want 8", len(infos))
+	}
+}
+
+// ---- KeyStore backends ----
+
+func TestKeyStoreConformance(t *testing.T) {
+	t.Run("Memory", func(t *testing.T) {
+		keystoretest.Run(t, func(t *testing.T) keystore.KeyStore { return keystore.NewMemoryStore() })
+	})
+
+	t.Run("FilePassphrase", func(t *testing.T) {
+		keystoretest.Run(t, func(t *testing.T) keystore.KeyStore {
+			s, err := keystore.NewFileStore(t.TempDir()+"/keys", []byte("passphrase"), fastPasswordParams(key.Scrypt))
+			if err != nil {
+				t.Fatalf("NewFileStore: %v", err)
+			}
+			return s
+		})
+	})
+
+	t.Run("FileWrappingKey", func(t *testing.T) {
+		keystoretest.Run(t, func(t *testing.T) keystore.KeyStore {
+			s, err := keystore.NewFileStoreWithKey(t.TempDir()+"/keys", make([]byte, keystore.KEKSize))
+			if err != nil {
+				t.Fatalf("NewFileStoreWithKey: %v", err)
+			}
+			return s
+		})
+	})
+}
+
+func TestFileStoreRotatePersists(t *testing.T) {
+	dir := t.TempDir() + "/keys"
+	kek := make([]byte, keystore.KEKSize)
+
+	var s keystore.KeyStore
+	s, err := keystore.NewFileStoreWithKey(dir, kek)
+	if err != nil {
+		t.Fatalf("NewFileStoreWithKey: %v", err)
+	}
+	k, err := key.GenerateKey(key.ECDSA256)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	if _, err := s.Put(keystore.Entry{KID: "signing", Key: k}); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	rotated, err := s.Rotate("signing")
+	if err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+
+	if s, err = keystore.NewFileStoreWithKey(dir, kek); err != nil {
+		t.Fatalf("reopen NewFileStoreWithKey: %v", err)
 	}
+	if e, err := s.Get("signing"); err != nil || !e.Key.Equal(rotated.Key) {
+		t.Errorf("rotated key was not persisted: %v", err)
+	}
+}
 
-	// JSON marshal round-trip
-	jb, err := json.Marshal(k)
+// ---- Shamir secret sharing ----
+
+func TestShamirSplitKey(t *testing.T) {
+	for _, kt := range []shared.KeyType{key.ED25519, key.ECDSA384, key.RSA2048} {
+		k, err := key.GenerateKey(kt)
+		if err != nil {
+			t.Fatalf("GenerateKey(%s): %v", kt, err)
+		}
+
+		shares, err := shamir.SplitKey(k, 5, 3)
+		if err != nil {
+			t.Fatalf("SplitKey(%s): %v", kt, err)
+		}
+		if len(shares) != 5 {
+			t.Fatalf("SplitKey returned %d shares, want 5", len(shares))
+		}
+
+		// every 3 of 5 shares, passed around as strings, reconstruct the key
+		for a := 0; a < 5; a++ {
+			for b := a + 1; b < 5; b++ {
+				for c := b + 1; c < 5; c++ {
+					var subset []*shamir.Share
+					for _, i := range []int{c, a, b} {
+						s, err := shamir.NewShareFromStr(shares[i].String())
+						if err != nil {
+							t.Fatalf("NewShareFromStr: %v", err)
+						}
+						if s.KeyType != kt || s.Threshold != 3 || s.Index != uint8(i+1) {
+							t.Fatalf("share %d decoded as %s %d/%d", i, s.KeyType, s.Index, s.Threshold)
+						}
+						subset = append(subset, s)
+					}
+
+					rk, err := shamir.CombineKey(subset)
+					if err != nil {
+						t.Fatalf("CombineKey(%s, %d %d %d): %v", kt, a, b, c, err)
+					}
+					if !rk.Equal(k) {
+						t.Fatalf("CombineKey(%s, %d %d %d) reconstructed a different key", kt, a, b, c)
+					}
+				}
+			}
+		}
+
+		if _, err := shamir.CombineKey(shares[:2]); !errors.Is(err, shamir.ErrNotEnoughShares) {
+			t.Errorf("CombineKey with 2 of 3 shares = %v, want ErrNotEnoughShares", err)
+		}
+
+		// all five shares work too
+		rk, err := shamir.CombineKey(shares)
+		if err != nil || !rk.Equal(k) {
+			t.Errorf("CombineKey with all shares: %v", err)
+		}
+		signed, err := rk.SignMessage([]byte("hello, world"))
+		if err != nil || !k.VerifyMessage([]byte("hello, world"), signed) {
+			t.Errorf("reconstructed %s key cannot sign: %v", kt, err)
+		}
+	}
+}
+
+func TestShamirSplitKeyExchange(t *testing.T) {
+	kx, err := key.GenerateKeyExchange(key.CURVE25519)
 	if err != nil {
-		t.Fatalf("%s: MarshalJSON: %v", kt, err)
+		t.Fatalf("GenerateKeyExchange: %v", err)
 	}
-	kFromJSON, err := key.NewKeyFromBytes(jb)
+	shares, err := shamir.SplitKeyExchange(kx, 3, 2)
 	if err != nil {
-		t.Fatalf("%s: NewKeyFromBytes(json): %v", kt, err)
+		t.Fatalf("SplitKeyExchange: %v", err)
 	}
-	if kFromJSON.KeyType() != kt {
-		t.Errorf("%s: JSON round-trip KeyType = %s, want %s", kt, kFromJSON.KeyType(), kt)
+	if shares[0].KeyXType != key.CURVE25519 {
+		t.Errorf("share type = %s, want curve25519", shares[0].KeyXType)
 	}
 
-	// Public key round-trip via string
-	kPub2, err := key.NewKeyFromStr(kPub.String())
+	rkx, err := shamir.CombineKeyExchange([]*shamir.Share{shares[2], shares[0]})
 	if err != nil {
-		t.Fatalf("%s: NewKeyFromStr(public): %v", kt, err)
+		t.Fatalf("CombineKeyExchange: %v", err)
 	}
-	if !kPub2.IsPublicKey() {
-		t.Errorf("%s: reconstructed public key should be public", kt)
+	if !rkx.Equal(kx) {
+		t.Error("CombineKeyExchange reconstructed a different key exchange")
+	}
+
+	if _, err := shamir.CombineKey(shares); !errors.Is(err, shamir.ErrMismatchedShares) {
+		t.Errorf("CombineKey with key exchange shares = %v, want ErrMismatchedShares", err)
 	}
 }
 
-func TestED25519(t *testing.T)  { testAsymKey(t, key.ED25519) }
-func TestECDSA256(t *testing.T) { testAsymKey(t, key.ECDSA256) }
-func TestECDSA384(t *testing.T) { testAsymKey(t, key.ECDSA384) }
-func TestECDSA521(t *testing.T) { testAsymKey(t, key.ECDSA521) }
-func TestRSA2048(t *testing.T)  { testAsymKey(t, key.RSA2048) }
+func TestShamirErrors(t *testing.T) {
+	k, _ := key.GenerateKey(key.ED25519)
 
-func TestRSA4096(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping slow RSA4096 generation")
+	for _, c := range []struct{ n, threshold int }{{5, 1}, {3, 4}, {256, 3}} {
+		if _, err := shamir.SplitKey(k, c.n, c.threshold); err == nil {
+			t.Errorf("SplitKey(%d, %d) should fail", c.n, c.threshold)
+		}
+	}
+	pub, _ := k.PublicKey()
+	if _, err := shamir.SplitKey(pub, 5, 3); err == nil {
+		t.Error("SplitKey of a public key should fail")
+	}
+
+	shares, _ := shamir.SplitKey(k, 5, 3)
+	other, _ := shamir.SplitKey(k, 5, 3)
+
+	// shares of different splits of the same key cannot be mixed
+	if _, err := shamir.CombineKey([]*shamir.Share{shares[0], shares[1], other[2]}); !errors.Is(err, shamir.ErrMismatchedShares) {
+		t.Errorf("CombineKey with mixed splits = %v, want ErrMismatchedShares", err)
+	}
+	if _, err := shamir.CombineKey([]*shamir.Share{shares[0], shares[1], shares[0]}); !errors.Is(err, shamir.ErrInvalidShare) {
+		t.Errorf("CombineKey with a duplicate share = %v, want ErrInvalidShare", err)
+	}
+
+	// a transcription error is caught by the checksum
+	b := shares[1].Bytes()
+	b[len(b)/2] ^= 0x01
+	if _, err := shamir.NewShareFromBytes(b); !errors.Is(err, shamir.ErrInvalidShare) {
+		t.Errorf("NewShareFromBytes with a flipped bit = %v, want ErrInvalidShare", err)
+	}
+	if _, err := shamir.NewShareFromStr("not a share"); !errors.Is(err, shamir.ErrInvalidShare) {
+		t.Errorf("NewShareFromStr = %v, want ErrInvalidShare", err)
+	}
+
+	// an altered share with a valid checksum does not reconstruct a different key
+	b = shares[1].Bytes()
+	b[len(b)-5] ^= 0x01
+	sum := sha256.Sum256(b[:len(b)-4])
+	copy(b[len(b)-4:], sum[:4])
+	altered, err := shamir.NewShareFromBytes(b)
+	if err != nil {
+		t.Fatalf("NewShareFromBytes: %v", err)
+	}
+	if _, err := shamir.CombineKey([]*shamir.Share{shares[0], altered, shares[2]}); !errors.Is(err, shamir.ErrInvalidShare) {
+		t.Errorf("CombineKey with an altered share = %v, want ErrInvalidShare", err)
+	}
+
+	// shares beyond the threshold are checked against the others
+	if _, err := shamir.CombineKey([]*shamir.Share{shares[0], shares[2], shares[3], altered}); !errors.Is(err, shamir.ErrInvalidShare) {
+		t.Errorf("CombineKey with an altered extra share = %v, want ErrInvalidShare", err)
+	}
+
+	// an altered key exchange share still reconstructs a valid scalar, the public key fingerprint catches it
+	kx, _ := key.GenerateKeyExchange(key.CURVE25519)
+	kxShares, _ := shamir.SplitKeyExchange(kx, 3, 2)
+	b = kxShares[0].Bytes()
+	b[len(b)-5] ^= 0x01
+	sum = sha256.Sum256(b[:len(b)-4])
+	copy(b[len(b)-4:], sum[:4])
+	if altered, err = shamir.NewShareFromBytes(b); err != nil {
+		t.Fatalf("NewShareFromBytes: %v", err)
+	}
+	if _, err := shamir.CombineKeyExchange([]*shamir.Share{altered, kxShares[1]}); !errors.Is(err, shamir.ErrInvalidShare) {
+		t.Errorf("CombineKeyExchange with an altered share = %v, want ErrInvalidShare", err)
 	}
-	testAsymKey(t, key.RSA4096)
 }
 
-func TestRSA8192(t *testing.T) {
-	if testing.Short() {
-		t.Skip("skipping slow RSA8192 generation")
+// ---- AES key wrap ----
+
+func TestKeyWrapVectors(t *testing.T) {
+	// RFC 3394 section 4.6, 256 bits of key data with a 256-bit KEK
+	kek := mustHex(t, "000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F")
+	data := mustHex(t, "00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F")
+	want := mustHex(t, "28C9F404C4B810F4CBCCB35CFB87F8263F5786E2D80ED326CBC7F0E71A99F43BFB988B9B7A02DD21")
+
+	wrapped, err := keywrap.Wrap(kek, data)
+	if err != nil {
+		t.Fatalf("Wrap: %v", err)
+	}
+	if !bytes.Equal(wrapped, want) {
+		t.Errorf("Wrap = %X, want %X", wrapped, want)
+	}
+	unwrapped, err := keywrap.Unwrap(kek, want)
+	if err != nil || !bytes.Equal(unwrapped, data) {
+		t.Errorf("Unwrap = %X, %v", unwrapped, err)
+	}
+
+	// the plaintexts of RFC 5649 section 6, which only has 192-bit KEK vectors, wrapped with a 256-bit KEK by OpenSSL id-aes256-wrap-pad
+	for _, v := range []struct{ data, wrapped string }{
+		{"c37b7e6492584340bed12207808941155068f738", "29b7fa191c2165684374eee9f74595e2a42bace75c425b3053efa26ffe1bb32f"},
+		{"466f7250617369", "443b17837bb39348610d19202df8a1f9"},
+	} {
+		data, want := mustHex(t, v.data), mustHex(t, v.wrapped)
+		wrapped, err := keywrap.WrapPad(kek, data)
+		if err != nil {
+			t.Fatalf("WrapPad: %v", err)
+		}
+		if !bytes.Equal(wrapped, want) {
+			t.Errorf("WrapPad(%s) = %x, want %x", v.data, wrapped, want)
+		}
+		unwrapped, err := keywrap.UnwrapPad(kek, want)
+		if err != nil || !bytes.Equal(unwrapped, data) {
+			t.Errorf("UnwrapPad(%s) = %x, %v", v.wrapped, unwrapped, err)
+		}
+	}
+}
+
+func TestKeyWrapIntegrity(t *testing.T) {
+	kek := bytes.Repeat([]byte{0x01}, 32)
+	other := bytes.Repeat([]byte{0x02}, 32)
+
+	for _, size := range []int{1, 7, 8, 9, 16, 20, 64} {
+		data := bytes.Repeat([]byte{0xab}, size)
+		wrapped, err := keywrap.WrapPad(kek, data)
+		if err != nil {
+			t.Fatalf("WrapPad(%d bytes): %v", size, err)
+		}
+		if unwrapped, err := keywrap.UnwrapPad(kek, wrapped); err != nil || !bytes.Equal(unwrapped, data) {
+			t.Errorf("UnwrapPad(%d bytes) = %x, %v", size, unwrapped, err)
+		}
+		if _, err := keywrap.UnwrapPad(other, wrapped); !errors.Is(err, keywrap.ErrUnwrap) {
+			t.Errorf("UnwrapPad(%d bytes) with the wrong KEK = %v, want ErrUnwrap", size, err)
+		}
+		wrapped[len(wrapped)-1] ^= 0x01
+		if _, err := keywrap.UnwrapPad(kek, wrapped); !errors.Is(err, keywrap.ErrUnwrap) {
+			t.Errorf("UnwrapPad(%d bytes) of altered data = %v, want ErrUnwrap", size, err)
+		}
+	}
+
+	wrapped, _ := keywrap.Wrap(kek, make([]byte, 24))
+	if _, err := keywrap.Unwrap(other, wrapped); !errors.Is(err, keywrap.ErrUnwrap) {
+		t.Errorf("Unwrap with the wrong KEK = %v, want ErrUnwrap", err)
+	}
+	if _, err := keywrap.Wrap(kek, make([]byte, 20)); err == nil {
+		t.Error("Wrap should refuse data that is not a multiple of 8 bytes")
+	}
+	if _, err := keywrap.Wrap(kek[:20], make([]byte, 16)); err == nil {
+		t.Error("Wrap should refuse an invalid AES key size")
+	}
+
+	// only AES-256 key-encryption keys are accepted
+	for _, size := range []int{16, 24} {
+		short := kek[:size]
+		if _, err := keywrap.Wrap(short, make([]byte, 16)); err == nil {
+			t.Errorf("Wrap should refuse a %d byte KEK", size)
+		}
+		if _, err := keywrap.WrapPad(short, make([]byte, 16)); err == nil {
+			t.Errorf("WrapPad should refuse a %d byte KEK", size)
+		}
+		if _, err := keywrap.UnwrapPad(short, wrapped); err == nil {
+			t.Errorf("UnwrapPad should refuse a %d byte KEK", size)
+		}
+		k, _ := key.GenerateKey(key.ED25519)
+		if _, err := keywrap.WrapKey(short, k, keywrap.PKCS8); err == nil {
+			t.Errorf("WrapKey should refuse a %d byte KEK", size)
+		}
+	}
+}
+
+func TestKeyWrapKeys(t *testing.T) {
+	kek := bytes.Repeat([]byte{0x42}, 32)
+
+	for _, kt := range []shared.KeyType{key.ED25519, key.ECDSA256, key.ECDSA521, key.RSA2048} {
+		k, err := key.GenerateKey(kt)
+		if err != nil {
+			t.Fatalf("GenerateKey(%s): %v", kt, err)
+		}
+		k.SetKeyID("exported")
+
+		for _, format := range []keywrap.Format{keywrap.PKCS8, keywrap.JWK} {
+			wrapped, err := keywrap.WrapKey(kek, k, format)
+			if err != nil {
+				t.Fatalf("WrapKey(%s, %s): %v", kt, format, err)
+			}
+			uk, err := keywrap.UnwrapKey(kek, wrapped, format, key.WithStrict())
+			if err != nil {
+				t.Fatalf("UnwrapKey(%s, %s): %v", kt, format, err)
+			}
+			if !uk.Equal(k) {
+				t.Errorf("UnwrapKey(%s, %s) returned a different key", kt, format)
+			}
+			if format == keywrap.JWK && uk.GetKeyID() != "exported" {
+				t.Errorf("UnwrapKey(%s, jwk) lost the kid", kt)
+			}
+		}
+
+		// PKCS #8 is understood by the standard library, as it would be by an HSM
+		wrapped, _ := keywrap.WrapKey(kek, k, keywrap.PKCS8)
+		der, err := keywrap.UnwrapPad(kek, wrapped)
+		if err != nil {
+			t.Fatalf("UnwrapPad: %v", err)
+		}
+		if _, err := x509.ParsePKCS8PrivateKey(der); err != nil {
+			t.Errorf("wrapped %s key is not PKCS #8: %v", kt, err)
+		}
+
+		if _, err := keywrap.UnwrapKey(bytes.Repeat([]byte{0x43}, 32), wrapped, keywrap.PKCS8); !errors.Is(err, keywrap.ErrUnwrap) {
+			t.Errorf("UnwrapKey with the wrong KEK = %v, want ErrUnwrap", err)
+		}
+	}
+
+	k, _ := key.GenerateKey(key.ED25519)
+	pub, _ := k.PublicKey()
+	if _, err := keywrap.WrapKey(kek, pub, keywrap.JWK); err == nil {
+		t.Error("WrapKey of a public key should fail")
+	}
+	if _, err := keywrap.WrapKey(kek, k, keywrap.Raw); err == nil {
+		t.Error("WrapKey should refuse the raw format")
+	}
+}
+
+func TestKeyWrapKeyExchanges(t *testing.T) {
+	kek := bytes.Repeat([]byte{0x42}, 32)
+
+	for _, kxt := range []shared.KeyXType{key.CURVE25519, key.ECDH256, key.ECDH384, key.ECDH521} {
+		kx, err := key.GenerateKeyExchange(kxt)
+		if err != nil {
+			t.Fatalf("GenerateKeyExchange(%s): %v", kxt, err)
+		}
+
+		for _, format := range []keywrap.Format{keywrap.PKCS8, keywrap.Raw} {
+			wrapped, err := keywrap.WrapKeyExchange(kek, kx, format)
+			if err != nil {
+				t.Fatalf("WrapKeyExchange(%s, %s): %v", kxt, format, err)
+			}
+			ukx, err := keywrap.UnwrapKeyExchange(kek, wrapped, format, key.WithStrict())
+			if err != nil {
+				t.Fatalf("UnwrapKeyExchange(%s, %s): %v", kxt, format, err)
+			}
+			if ukx.KeyType() != kxt || !ukx.Equal(kx) {
+				t.Errorf("UnwrapKeyExchange(%s, %s) returned a different key exchange", kxt, format)
+			}
+		}
+	}
+
+	// a wrapped signing key is not a key exchange, and the other way around
+	k, _ := key.GenerateKey(key.ED25519)
+	wrapped, _ := keywrap.WrapKey(kek, k, keywrap.PKCS8)
+	if _, err := keywrap.UnwrapKeyExchange(kek, wrapped, keywrap.PKCS8); err == nil {
+		t.Error("UnwrapKeyExchange of an ED25519 key should fail")
+	}
+	kx, _ := key.GenerateKeyExchange(key.CURVE25519)
+	wrapped, _ = keywrap.WrapKeyExchange(kek, kx, keywrap.PKCS8)
+	if _, err := keywrap.UnwrapKey(kek, wrapped, keywrap.PKCS8); err == nil {
+		t.Error("UnwrapKey of a Curve25519 key exchange should fail")
+	}
+}
+
+// ---- Symmetric oct keys ----
+
+func TestOctHMAC(t *testing.T) {
+	msg := []byte("hello, world")
+
+	for _, kt := range []shared.SymKeyType{key.HS256, key.HS384, key.HS512} {
+		k, err := key.GenerateSymmetricKey(kt)
+		if err != nil {
+			t.Fatalf("GenerateKey(%s): %v", kt, err)
+		}
+
+		signed, err := k.SignMessage(msg)
+		if err != nil {
+			t.Fatalf("SignMessage(%s): %v", kt, err)
+		}
+
+		// the signature is the HMAC of the message, as used by JWS
+		secret := k.SecretKeyInstance()
+		mac := hmac.New(kt.Hash().New, secret)
+		mac.Write(msg)
+		if !bytes.Equal(signed, mac.Sum(nil)) {
+			t.Errorf("%s signature is not the HMAC of the message", kt)
+		}
+		if len(secret)*8 != kt.Bits() {
+			t.Errorf("%s key is %d bits, want %d", kt, len(secret)*8, kt.Bits())
+		}
+
+		if !k.VerifyMessage(msg, signed) {
+			t.Errorf("%s VerifyMessage failed", kt)
+		}
+		if k.VerifyMessage([]byte("hello, world!"), signed) {
+			t.Errorf("%s VerifyMessage accepted a different message", kt)
+		}
+		if err := k.VerifyErr(signed[1:], msg); !errors.Is(err, key.ErrMalformedSignature) {
+			t.Errorf("%s VerifyErr of a short signature = %v, want ErrMalformedSignature", kt, err)
+		}
+		signed[0] ^= 0x01
+		if err := k.VerifyErr(signed, msg); !errors.Is(err, key.ErrSignatureMismatch) {
+			t.Errorf("%s VerifyErr of an altered signature = %v, want ErrSignatureMismatch", kt, err)
+		}
+
+		streamed, err := k.SignReader(bytes.NewReader(msg))
+		if err != nil || !k.VerifyReader(bytes.NewReader(msg), streamed) || !k.VerifyMessage(msg, streamed) {
+			t.Errorf("%s SignReader does not match SignMessage: %v", kt, err)
+		}
+	}
+}
+
+func TestOctJWK(t *testing.T) {
+	for _, kt := range []shared.SymKeyType{key.HS256, key.HS512, key.A128GCM, key.A256GCM} {
+		k, err := key.GenerateSymmetricKey(kt)
+		if err != nil {
+			t.Fatalf("GenerateKey(%s): %v", kt, err)
+		}
+
+		kb, err := k.Bytes()
+		if err != nil {
+			t.Fatalf("Bytes(%s): %v", kt, err)
+		}
+		var m struct {
+			Kty string `json:"kty"`
+			Alg string `json:"alg"`
+			K   string `json:"k"`
+			Kid string `json:"kid"`
+		}
+		if err := json.Unmarshal(kb, &m); err != nil {
+			t.Fatalf("json.Unmarshal: %v", err)
+		}
+		// A128GCM and A256GCM are JWE `enc` values, AES-GCM keys are written without `alg`
+		alg := strings.ToUpper(kt.String())
+		if kt.Hash() == 0 {
+			alg = ""
+		}
+		if m.Kty != "oct" || m.Alg != alg || m.K == "" || m.Kid == "" {
+			t.Errorf("%s JWK = %s", kt, kb)
+		}
+
+		k2, err := key.NewSymmetricKeyFromBytes(kb, kt, key.WithStrict())
+		if err != nil {
+			t.Fatalf("NewSymmetricKeyFromBytes(%s): %v", kt, err)
+		}
+		if _, err := key.NewKeyFromBytes(kb); err == nil || !strings.Contains(err.Error(), "NewSymmetricKeyFromBytes") {
+			t.Errorf("NewKeyFromBytes(%s) = %v, want an error pointing to NewSymmetricKeyFromBytes", kt, err)
+		}
+		if k2.KeyType() != kt || !k2.Equal(k) || k2.GetKeyID() != m.Kid {
+			t.Errorf("%s key changed after parsing its JWK", kt)
+		}
+
+		// the default key ID is not the JWK thumbprint, which is a plain hash of the secret (RFC 7638)
+		thumbprint := sha256.Sum256([]byte(`{"k":"` + m.K + `","kty":"oct"}`))
+		if m.Kid == base64.RawURLEncoding.EncodeToString(thumbprint[:]) {
+			t.Errorf("%s key ID is the JWK thumbprint", kt)
+		}
+		if k.String() != k.String() {
+			t.Errorf("%s default key ID is not stable", kt)
+		}
+	}
+
+	// RFC 7515 appendix A.1
+	k, err := key.NewSymmetricKeyFromStr(`{"kty":"oct","alg":"HS256","k":"AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow"}`, key.HS256)
+	if err != nil {
+		t.Fatalf("NewSymmetricKeyFromStr: %v", err)
+	}
+	signed, err := base64.RawURLEncoding.DecodeString("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if err != nil {
+		t.Fatalf("DecodeString: %v", err)
+	}
+	if !k.VerifyMessage([]byte("eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9.eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ"), signed) {
+		t.Error("RFC 7515 HS256 example does not verify")
+	}
+
+	// the caller gives the type, `alg` is optional but must match it and the key must be long enough for it
+	const secret = `"k":"AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr8"` // 32 bytes
+	for _, tc := range []struct {
+		jwk string
+		kt  shared.SymKeyType
+		ok  bool
+	}{
+		{`{"kty":"oct",` + secret + `}`, key.HS256, true},
+		{`{"kty":"oct",` + secret + `}`, key.A256GCM, true},
+		{`{"kty":"oct","alg":"HS256",` + secret + `}`, key.HS256, true},
+		{`{"kty":"oct","alg":"dir",` + secret + `}`, key.A256GCM, true},
+		{`{"kty":"oct","alg":"A256GCMKW",` + secret + `}`, key.A256GCM, true},
+		{`{"kty":"oct","alg":"HS256",` + secret + `}`, key.A256GCM, false},
+		{`{"kty":"oct","alg":"A256GCM",` + secret + `}`, key.A256GCM, false},
+		{`{"kty":"oct","alg":"A128GCMKW",` + secret + `}`, key.A256GCM, false},
+		{`{"kty":"oct","alg":"dir",` + secret + `}`, key.HS256, false},
+		{`{"kty":"oct","alg":"HS512",` + secret + `}`, key.HS512, false},
+		{`{"kty":"oct",` + secret + `}`, key.A128GCM, false},
+		{`{"kty":"oct",` + secret + `}`, shared.SymKeyType(0), false},
+	} {
+		k, err := key.NewSymmetricKeyFromStr(tc.jwk, tc.kt)
+		if tc.ok && (err != nil || k.KeyType() != tc.kt) {
+			t.Errorf("NewSymmetricKeyFromStr(%s, %s) = %v", tc.jwk, tc.kt, err)
+		}
+		if !tc.ok && err == nil {
+			t.Errorf("NewSymmetricKeyFromStr(%s, %s) should fail", tc.jwk, tc.kt)
+		}
+	}
+}
+
+func TestOctAEAD(t *testing.T) {
+	k, err := key.GenerateSymmetricKey(key.A256GCM)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+
+	// AES-GCM keys encrypt and cannot sign
+	if _, err := k.SignMessage([]byte("hello, world")); err == nil {
+		t.Error("A256GCM keys should not sign")
+	}
+
+	aead, err := k.AEAD()
+	if err != nil {
+		t.Fatalf("AEAD: %v", err)
+	}
+	nonce := make([]byte, aead.NonceSize())
+	sealed := aead.Seal(nil, nonce, []byte("hello, world"), nil)
+	if opened, err := aead.Open(nil, nonce, sealed, nil); err != nil || string(opened) != "hello, world" {
+		t.Errorf("Open = %q, %v", opened, err)
+	}
+
+	hs, _ := key.GenerateSymmetricKey(key.HS256)
+	if _, err := hs.AEAD(); err == nil {
+		t.Error("HS256 keys should not return an AEAD")
+	}
+
+	k.Destroy()
+	if _, err := k.AEAD(); !errors.Is(err, key.ErrKeyDestroyed) {
+		t.Errorf("AEAD after Destroy = %v, want ErrKeyDestroyed", err)
+	}
+	if _, err := hs.SignMessage(nil); err != nil {
+		t.Errorf("SignMessage: %v", err)
+	}
+	hs.Destroy()
+	if _, err := hs.SignMessage(nil); !errors.Is(err, key.ErrKeyDestroyed) {
+		t.Errorf("SignMessage after Destroy = %v, want ErrKeyDestroyed", err)
+	}
+}
+
+func TestDeriveSymmetricKey(t *testing.T) {
+	alice, _ := key.GenerateKeyExchange(key.ECDH256)
+	bob, _ := key.GenerateKeyExchange(key.ECDH256)
+	alicePub, bobPub := alice.PublicKey(), bob.PublicKey()
+
+	ka, err := key.DeriveSymmetricKey(key.HS256, alice, bobPub, nil, "tokens")
+	if err != nil {
+		t.Fatalf("DeriveSymmetricKey: %v", err)
+	}
+	kb, err := key.DeriveSymmetricKey(key.HS256, bob, alicePub, nil, "tokens")
+	if err != nil {
+		t.Fatalf("DeriveSymmetricKey: %v", err)
+	}
+	if !ka.Equal(kb) {
+		t.Fatal("both peers should derive the same key")
+	}
+
+	signed, _ := ka.SignMessage([]byte("hello, world"))
+	if !kb.VerifyMessage([]byte("hello, world"), signed) {
+		t.Error("peer key does not verify")
+	}
+
+	// a different purpose gives an independent key
+	other, _ := key.DeriveSymmetricKey(key.HS256, alice, bobPub, nil, "cookies")
+	if other.Equal(ka) {
+		t.Error("different info should derive a different key")
+	}
+
+	// HKDF-SHA256 of the shared secret
+	secret, _ := alice.SharedSecret(bobPub)
+	want, _ := hkdf.Key(sha256.New, secret, nil, "tokens", 32)
+	if !bytes.Equal(ka.SecretKeyInstance(), want) {
+		t.Error("derived key is not HKDF-SHA256 of the shared secret")
+	}
+
+	aes, err := key.DeriveSymmetricKey(key.A128GCM, alice, bobPub, []byte("salt"), "encryption")
+	if err != nil || aes.KeyType() != key.A128GCM || len(aes.SecretKeyInstance()) != 16 {
+		t.Errorf("DeriveSymmetricKey(A128GCM) = %v", err)
+	}
+
+	if _, err := key.DeriveSymmetricKey(shared.SymKeyType(0), alice, bobPub, nil, ""); err == nil {
+		t.Error("DeriveSymmetricKey should refuse unknown key types")
+	}
+}
+
+// ---- Encryption keyed from a key exchange ----
+
+func TestEncryptDecrypt(t *testing.T) {
+	msg := []byte("hello, world")
+	aad := []byte("message 1")
+
+	for _, kxt := range []shared.KeyXType{key.CURVE25519, key.ECDH256, key.ECDH521} {
+		for _, c := range []key.Cipher{key.AES256GCM, key.XChaCha20Poly1305} {
+			alice, _ := key.GenerateKeyExchange(kxt)
+			bob, _ := key.GenerateKeyExchange(kxt)
+
+			ciphertext, err := key.Encrypt(alice, bob.PublicKey(), msg, aad, c)
+			if err != nil {
+				t.Fatalf("Encrypt(%s, %s): %v", kxt, c, err)
+			}
+			if ciphertext[0] != 1 || key.Cipher(ciphertext[1]) != c {
+				t.Errorf("Encrypt(%s, %s) header = %x", kxt, c, ciphertext[:2])
+			}
+			if bytes.Contains(ciphertext, msg) {
+				t.Errorf("Encrypt(%s, %s) did not encrypt", kxt, c)
+			}
+
+			plaintext, err := key.Decrypt(bob, alice.PublicKey(), ciphertext, aad)
+			if err != nil || !bytes.Equal(plaintext, msg) {
+				t.Fatalf("Decrypt(%s, %s) = %q, %v", kxt, c, plaintext, err)
+			}
+
+			// each direction has its own key, a ciphertext reflected back to its sender does not decrypt
+			if _, err := key.Decrypt(alice, bob.PublicKey(), ciphertext, aad); !errors.Is(err, key.ErrDecrypt) {
+				t.Errorf("Decrypt(%s, %s) of a reflected ciphertext = %v, want ErrDecrypt", kxt, c, err)
+			}
+
+			// random nonces give a different ciphertext every time
+			again, _ := key.Encrypt(alice, bob.PublicKey(), msg, aad, c)
+			if bytes.Equal(again, ciphertext) {
+				t.Errorf("Encrypt(%s, %s) repeated a ciphertext", kxt, c)
+			}
+
+			if _, err := key.Decrypt(bob, alice.PublicKey(), ciphertext, []byte("message 2")); !errors.Is(err, key.ErrDecrypt) {
+				t.Errorf("Decrypt(%s, %s) with different aad = %v, want ErrDecrypt", kxt, c, err)
+			}
+			for i := range ciphertext {
+				altered := bytes.Clone(ciphertext)
+				altered[i] ^= 0x01
+				if _, err := key.Decrypt(bob, alice.PublicKey(), altered, aad); err == nil {
+					t.Errorf("Decrypt(%s, %s) accepted a ciphertext altered at byte %d", kxt, c, i)
+					break
+				}
+			}
+			if _, err := key.Decrypt(bob, alice.PublicKey(), ciphertext[:20], aad); !errors.Is(err, key.ErrDecrypt) {
+				t.Errorf("Decrypt(%s, %s) of a truncated ciphertext = %v, want ErrDecrypt", kxt, c, err)
+			}
+		}
+	}
+}
+
+func TestEncryptTranscriptBinding(t *testing.T) {
+	alice, _ := key.GenerateKeyExchange(key.CURVE25519)
+	bob, _ := key.GenerateKeyExchange(key.CURVE25519)
+	eve, _ := key.GenerateKeyExchange(key.CURVE25519)
+
+	ciphertext, err := key.Encrypt(alice, bob.PublicKey(), []byte("hello, bob"), nil, key.AES256GCM)
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+
+	// only the intended pair of keys decrypts
+	if _, err := key.Decrypt(eve, alice.PublicKey(), ciphertext, nil); !errors.Is(err, key.ErrDecrypt) {
+		t.Errorf("Decrypt by another recipient = %v, want ErrDecrypt", err)
+	}
+	if _, err := key.Decrypt(bob, eve.PublicKey(), ciphertext, nil); !errors.Is(err, key.ErrDecrypt) {
+		t.Errorf("Decrypt claiming another sender = %v, want ErrDecrypt", err)
+	}
+
+	// a reply from bob is keyed for the other direction, so it cannot be passed off as a message from alice
+	reply, _ := key.Encrypt(bob, alice.PublicKey(), []byte("hello, alice"), nil, key.AES256GCM)
+	if _, err := key.Decrypt(bob, alice.PublicKey(), reply, nil); !errors.Is(err, key.ErrDecrypt) {
+		t.Errorf("Decrypt of a reflected reply = %v, want ErrDecrypt", err)
+	}
+	if plaintext, err := key.Decrypt(alice, bob.PublicKey(), reply, nil); err != nil || string(plaintext) != "hello, alice" {
+		t.Errorf("Decrypt of a reply = %q, %v", plaintext, err)
+	}
+
+	// the key is not the raw shared secret, and is bound to the cipher
+	secret, _ := alice.SharedSecret(bob.PublicKey())
+	block, _ := aes.NewCipher(secret)
+	gcm, _ := cipher.NewGCMWithRandomNonce(block)
+	if _, err := gcm.Open(nil, nil, ciphertext[2:], ciphertext[:2]); err == nil {
+		t.Error("ciphertext should not be encrypted with the raw shared secret")
+	}
+	switched := bytes.Clone(ciphertext)
+	switched[1] = byte(key.XChaCha20Poly1305)
+	if _, err := key.Decrypt(bob, alice.PublicKey(), switched, nil); !errors.Is(err, key.ErrDecrypt) {
+		t.Errorf("Decrypt with a switched cipher = %v, want ErrDecrypt", err)
+	}
+
+	versioned := bytes.Clone(ciphertext)
+	versioned[0] = 2
+	if _, err := key.Decrypt(bob, alice.PublicKey(), versioned, nil); err == nil {
+		t.Error("Decrypt should refuse an unknown version")
+	}
+	if _, err := key.Encrypt(alice, bob.PublicKey(), nil, nil, key.Cipher(9)); err == nil {
+		t.Error("Encrypt should refuse an unknown cipher")
+	}
+
+	p256, _ := key.GenerateKeyExchange(key.ECDH256)
+	if _, err := key.Encrypt(alice, p256.PublicKey(), nil, nil, key.AES256GCM); err == nil {
+		t.Error("Encrypt should refuse peers of a different type")
+	}
+	if _, err := key.Encrypt(alice.PublicKey(), bob.PublicKey(), nil, nil, key.AES256GCM); err == nil {
+		t.Error("Encrypt should need a private key exchange")
 	}
-	testAsymKey(t, key.RSA8192)
 }
 
 // ---- Parse from fixed JWK strings ----
@@ -434,6 +3367,7 @@ func TestGetKeyType(t *testing.T) {
 		{"rsa4096", key.RSA4096},
 		{"rsa8192", key.RSA8192},
 		{"ECDSA384", key.ECDSA384}, // case-insensitive
+		{"hs256", shared.KeyType(0)},
 		{"unknown", shared.KeyType(0)},
 	}
 	for _, tc := range cases {
@@ -444,6 +3378,26 @@ func TestGetKeyType(t *testing.T) {
 	}
 }
 
+func TestGetSymKeyType(t *testing.T) {
+	cases := []struct {
+		name string
+		want shared.SymKeyType
+	}{
+		{"hs256", key.HS256},
+		{"hs384", key.HS384},
+		{"hs512", key.HS512},
+		{"a128gcm", key.A128GCM},
+		{"A256GCM", key.A256GCM}, // case-insensitive
+		{"ed25519", shared.SymKeyType(0)},
+	}
+	for _, tc := range cases {
+		got := key.GetSymKeyType(tc.name)
+		if got != tc.want {
+			t.Errorf("GetSymKeyType(%q) = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
 func TestGetKeyXType(t *testing.T) {
 	cases := []struct {
 		name string
@@ -483,6 +3437,22 @@ func TestKeyTypeJSON(t *testing.T) {
 	}
 }
 
+func TestSymKeyTypeJSON(t *testing.T) {
+	for _, st := range []shared.SymKeyType{key.HS256, key.HS384, key.HS512, key.A128GCM, key.A256GCM} {
+		b, err := json.Marshal(st)
+		if err != nil {
+			t.Fatalf("json.Marshal(%s): %v", st, err)
+		}
+		var st2 shared.SymKeyType
+		if err := json.Unmarshal(b, &st2); err != nil {
+			t.Fatalf("json.Unmarshal(%s): %v", st, err)
+		}
+		if st2 != st {
+			t.Errorf("SymKeyType JSON round-trip: got %s, want %s", st2, st)
+		}
+	}
+}
+
 func TestKeyXTypeJSON(t *testing.T) {
 	types := []shared.KeyXType{key.CURVE25519, key.ECDH256, key.ECDH384, key.ECDH521}
 	for _, kxt := range types {
@@ -1,11 +1,15 @@
 package ec
 
 import (
+	"bytes"
+	"crypto"
 	"crypto/ecdsa"
-	"crypto/rand"
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/lestrrat-go/jwx/v3/jwk"
 	"github.com/svicknesh/key/v2/shared"
@@ -17,6 +21,11 @@ type K struct {
 	pub           *ecdsa.PublicKey
 	isPriv, isPub bool
 	kid           string
+	deterministic bool
+	encoding      Encoding
+	destroyed     bool
+
+	lifetime *shared.Lifetime // validity window and usage counter enforced by `Sign`, shared by copies of K
 }
 
 // Bytes - returns JSON encoded bytes of the key
@@ -27,6 +36,8 @@ func (k *K) Bytes() (bytes []byte, err error) {
 		in = k.priv
 	} else if k.isPub {
 		in = k.pub
+	} else if k.destroyed {
+		return nil, fmt.Errorf("ecdsa-bytes: %w", shared.ErrKeyDestroyed)
 	} else {
 		return nil, fmt.Errorf("ecdsa-bytes: neither public nor private key found")
 	}
@@ -46,6 +57,10 @@ func (k *K) Bytes() (bytes []byte, err error) {
 		jk.Set(jwk.KeyIDKey, k.kid)
 	}
 
+	if err = k.lifetime.WriteJWK(jk.Set); nil != err {
+		return nil, fmt.Errorf("ecdsa-bytes: error setting validity -> %w", err)
+	}
+
 	return json.Marshal(jk)
 }
 
@@ -63,7 +78,15 @@ func (k *K) PublicKey() (kPub shared.Key, err error) {
 		return nil, errors.New("ecdsa-publickey: no private key exists to extract public key")
 	}
 
-	return New(k.priv.Public())
+	kPub, err = New(k.priv.Public())
+	if nil != err {
+		return nil, err
+	}
+	kPub.(*K).encoding = k.encoding // the public key verifies signatures in the same encoding
+	kPub.(*K).kid = k.kid
+	kPub.(*K).SetValidity(k.Validity()) // verifiers see the same validity window
+
+	return
 }
 
 // PrivateKeyInstance - returns actual instance of private key of type
@@ -101,8 +124,37 @@ func (k *K) KeyType() (kt shared.KeyType) {
 	return k.kt
 }
 
-// Sign - signs the given hashed data using the ECDSA private key
-func (k *K) Sign(hashed []byte) (signed []byte, err error) {
+// Sign - signs the given hashed data using the ECDSA private key, deterministically if enabled with `SetDeterministic` and encoded as selected with `SetEncoding`
+func (k *K) Sign(hashed []byte, opts ...shared.Option) (signed []byte, err error) {
+	return k.sign(hashed, k.deterministic, shared.NewOptions(opts...))
+}
+
+// SignDeterministic - signs the given hashed data using RFC 6979 deterministic ECDSA regardless of the key setting
+func (k *K) SignDeterministic(hashed []byte) (signed []byte, err error) {
+	return k.sign(hashed, true, shared.NewOptions())
+}
+
+// SetDeterministic - selects RFC 6979 deterministic signatures for every `Sign` done with this key
+func (k *K) SetDeterministic(deterministic bool) {
+	k.deterministic = deterministic
+}
+
+// SetEncoding - selects the signature encoding produced by `Sign` and expected by `Verify`
+func (k *K) SetEncoding(encoding Encoding) {
+	k.encoding = encoding
+}
+
+// Encoding - returns the signature encoding used by this key
+func (k *K) Encoding() (encoding Encoding) {
+	return k.encoding
+}
+
+// sign - signs the given hashed data, the nonce is derived from the key and hashed data per RFC 6979 when `deterministic` is set
+func (k *K) sign(hashed []byte, deterministic bool, o *shared.Options) (signed []byte, err error) {
+
+	if k.destroyed {
+		return nil, fmt.Errorf("ecdsa-sign: %w", shared.ErrKeyDestroyed)
+	}
 
 	if !k.isPriv {
 		return nil, fmt.Errorf("ecdsa-sign: private key does not exist for signing data")
@@ -112,9 +164,42 @@ func (k *K) Sign(hashed []byte) (signed []byte, err error) {
 		return nil, fmt.Errorf("ecdsa-sign: hashed input too short (%d bytes)", len(hashed))
 	}
 
-	signed, err = ecdsa.SignASN1(rand.Reader, k.priv, hashed)
+	if err = o.CheckFIPSOptions(); nil != err {
+		return nil, fmt.Errorf("ecdsa-sign: %w", err)
+	}
+
+	if err = k.lifetime.Use(); nil != err {
+		return nil, fmt.Errorf("ecdsa-sign: %w", err)
+	}
+	defer func() {
+		// a failed signature does not count against the usage limit
+		if nil != err {
+			k.lifetime.Refund()
+		}
+	}()
+
+	if deterministic {
+		var h crypto.Hash
+		h, err = hashForLength(len(hashed))
+		if nil != err {
+			return nil, fmt.Errorf("ecdsa-sign: %w", err)
+		}
+
+		// a nil random source makes the standard library use RFC 6979
+		signed, err = k.priv.Sign(nil, hashed, h)
+	} else {
+		signed, err = ecdsa.SignASN1(o.Rand, k.priv, hashed)
+	}
+
 	if nil != err {
-		err = fmt.Errorf("ecdsa-sign: ECDSA signature generation failed -> %w", err)
+		return nil, fmt.Errorf("ecdsa-sign: ECDSA signature generation failed -> %w", err)
+	}
+
+	if k.encoding == EncodingRaw {
+		signed, err = ASN1ToRaw(signed, k.kt)
+		if nil != err {
+			return nil, fmt.Errorf("ecdsa-sign: %w", err)
+		}
 	}
 
 	return
@@ -122,11 +207,62 @@ func (k *K) Sign(hashed []byte) (signed []byte, err error) {
 
 // Verify - verifies the signed data of the given hashed data using the ECDSA public key
 func (k *K) Verify(signed []byte, hashed []byte) (ok bool) {
-	if !k.isPub {
+	return k.VerifyErr(signed, hashed) == nil
+}
+
+// VerifyErr - verifies the signed data of the given hashed data using the ECDSA public key, returning the reason for failure
+func (k *K) VerifyErr(signed []byte, hashed []byte) (err error) {
+	pub, ok := k.PublicKeyInstance().(*ecdsa.PublicKey) // private keys verify using their public half
+	if !ok {
+		return fmt.Errorf("ecdsa-verify: %w", shared.ErrNoPublicKey)
+	}
+
+	if k.encoding == EncodingRaw {
+		signed, err = RawToASN1(signed, k.kt)
+	} else {
+		_, _, err = parseASN1(signed)
+	}
+	if nil != err {
+		return fmt.Errorf("ecdsa-verify: %w -> %w", shared.ErrMalformedSignature, err)
+	}
+
+	if !ecdsa.VerifyASN1(pub, hashed, signed) {
+		return fmt.Errorf("ecdsa-verify: %w", shared.ErrSignatureMismatch)
+	}
+
+	return
+}
+
+// SignMessage - hashes the given message with the digest matching the curve and signs it using the ECDSA private key
+func (k *K) SignMessage(msg []byte, opts ...shared.Option) (signed []byte, err error) {
+	return k.SignReader(bytes.NewReader(msg), opts...)
+}
+
+// VerifyMessage - hashes the given message with the digest matching the curve and verifies the signed data using the ECDSA public key
+func (k *K) VerifyMessage(msg []byte, signed []byte) (ok bool) {
+	return k.VerifyReader(bytes.NewReader(msg), signed)
+}
+
+// SignReader - hashes the message read from `rd` with the digest matching the curve and signs it using the ECDSA private key
+func (k *K) SignReader(rd io.Reader, opts ...shared.Option) (signed []byte, err error) {
+
+	digest, err := shared.Digest(k.kt, rd)
+	if nil != err {
+		return nil, fmt.Errorf("ecdsa-signreader: %w", err)
+	}
+
+	return k.Sign(digest, opts...)
+}
+
+// VerifyReader - hashes the message read from `rd` with the digest matching the curve and verifies the signed data using the ECDSA public key
+func (k *K) VerifyReader(rd io.Reader, signed []byte) (ok bool) {
+
+	digest, err := shared.Digest(k.kt, rd)
+	if nil != err {
 		return
 	}
 
-	return ecdsa.VerifyASN1(k.pub, hashed, signed)
+	return k.Verify(signed, digest)
 }
 
 // MarshalJSON - marshals this Key into a JSON
@@ -144,3 +280,124 @@ func (k *K) SetKeyID(kid string) (err error) {
 func (k *K) GetKeyID() (kid string) {
 	return k.kid
 }
+
+// SetValidity - sets the window during which the key may sign and its maximum number of uses
+func (k *K) SetValidity(v shared.Validity) {
+	k.lifetime.SetValidity(v)
+}
+
+// Validity - returns the window during which the key may sign and its maximum number of uses
+func (k *K) Validity() (v shared.Validity) {
+	return k.lifetime.Validity()
+}
+
+// Uses - returns the number of signatures made with the key
+func (k *K) Uses() (uses uint64) {
+	return k.lifetime.Uses()
+}
+
+// SetExpiryWarning - calls `fn` from `Sign` when the key is used `within` the given duration of its expiry
+func (k *K) SetExpiryWarning(within time.Duration, fn shared.ExpiryWarning) {
+	k.lifetime.SetExpiryWarning(within, fn)
+}
+
+// Destroy - zeroes the private scalar and drops all key material, every later use of the key fails.
+// The standard library keeps its own copy of the scalar inside `ecdsa.PrivateKey` which cannot be zeroed, it is released for garbage collection instead.
+func (k *K) Destroy() {
+	if nil != k.priv && nil != k.priv.D {
+		clear(k.priv.D.Bits())
+		k.priv.D.SetInt64(0)
+	}
+	k.priv, k.pub = nil, nil
+	k.isPriv, k.isPub = false, false
+	k.destroyed = true
+}
+
+// Equal - returns if `other` holds the same key material in constant time, both must be private or both public keys and the key ID is ignored
+func (k *K) Equal(other shared.Key) (ok bool) {
+	if nil == other || other.KeyType() != k.kt || k.isPriv != other.IsPrivateKey() {
+		return false
+	}
+
+	if !k.isPriv {
+		return k.PublicEqual(other)
+	}
+
+	priv, ok := other.PrivateKeyInstance().(*ecdsa.PrivateKey)
+	if !ok {
+		return false
+	}
+
+	b1, err1 := k.priv.Bytes()
+	b2, err2 := priv.Bytes()
+
+	return nil == err1 && nil == err2 && subtle.ConstantTimeCompare(b1, b2) == 1
+}
+
+// PublicEqual - returns if the public key of `other` matches the public key of this key in constant time, private keys compare using their public half
+func (k *K) PublicEqual(other shared.Key) (ok bool) {
+	if nil == other || other.KeyType() != k.kt {
+		return false
+	}
+
+	pub1, ok1 := k.PublicKeyInstance().(*ecdsa.PublicKey)
+	pub2, ok2 := other.PublicKeyInstance().(*ecdsa.PublicKey)
+	if !ok1 || !ok2 {
+		return false
+	}
+
+	b1, err1 := pub1.Bytes()
+	b2, err2 := pub2.Bytes()
+
+	return nil == err1 && nil == err2 && subtle.ConstantTimeCompare(b1, b2) == 1
+}
+
+// Validate - checks the public key is a point on the curve of the key type, and that a private scalar is in range and matches its public key
+func (k *K) Validate() (err error) {
+
+	if k.destroyed {
+		return fmt.Errorf("ecdsa-validate: %w", shared.ErrKeyDestroyed)
+	}
+
+	pub, ok := k.PublicKeyInstance().(*ecdsa.PublicKey)
+	if !ok || nil == pub {
+		return fmt.Errorf("ecdsa-validate: %w: neither public nor private key found", shared.ErrInvalidKey)
+	}
+
+	curve, err := curveFor(k.kt)
+	if nil != err {
+		return fmt.Errorf("ecdsa-validate: %w: %w", shared.ErrInvalidKey, err)
+	}
+
+	if pub.Curve != curve {
+		return fmt.Errorf("ecdsa-validate: %w: curve %s does not match key type %s", shared.ErrInvalidKey, pub.Curve.Params().Name, k.kt)
+	}
+
+	// parsing rejects the point at infinity and points that are not on the curve, the NIST curves have prime order so no subgroup check is needed
+	pb, err := pub.Bytes()
+	if nil == err {
+		_, err = ecdsa.ParseUncompressedPublicKey(curve, pb)
+	}
+	if nil != err {
+		return fmt.Errorf("ecdsa-validate: %w: %w", shared.ErrInvalidKey, err)
+	}
+
+	if k.isPriv {
+		db, err := k.priv.Bytes()
+		if nil != err {
+			return fmt.Errorf("ecdsa-validate: %w: %w", shared.ErrInvalidKey, err)
+		}
+		defer clear(db)
+
+		priv, err := ecdsa.ParseRawPrivateKey(curve, db)
+		if nil != err {
+			return fmt.Errorf("ecdsa-validate: %w: %w", shared.ErrInvalidKey, err)
+		}
+
+		if !priv.PublicKey.Equal(pub) {
+			return fmt.Errorf("ecdsa-validate: %w: public key does not match private key", shared.ErrInvalidKey)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,102 @@
+package ec
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/svicknesh/key/v2/shared"
+	"golang.org/x/crypto/cryptobyte"
+	"golang.org/x/crypto/cryptobyte/asn1"
+)
+
+// Encoding - signature encoding used by ECDSA keys
+type Encoding uint8
+
+const (
+	// EncodingASN1 - ASN.1 DER encoded signature, this is the default
+	EncodingASN1 Encoding = iota
+
+	// EncodingRaw - fixed length IEEE P1363 `r||s` signature used by WebCrypto, JOSE and COSE
+	EncodingRaw
+)
+
+// ASN1ToRaw - converts an ASN.1 DER encoded signature to the fixed length `r||s` encoding sized by the curve of the key type
+func ASN1ToRaw(sig []byte, kt shared.KeyType) (raw []byte, err error) {
+
+	size, err := scalarSize(kt)
+	if nil != err {
+		return nil, fmt.Errorf("ecdsa-asn1toraw: %w", err)
+	}
+
+	r, s, err := parseASN1(sig)
+	if nil != err {
+		return nil, fmt.Errorf("ecdsa-asn1toraw: %w", err)
+	}
+
+	if len(r) > size || len(s) > size {
+		return nil, fmt.Errorf("ecdsa-asn1toraw: signature values too large for %s", kt)
+	}
+
+	// left pad both values to the size of the curve
+	raw = make([]byte, 2*size)
+	copy(raw[size-len(r):size], r)
+	copy(raw[2*size-len(s):], s)
+
+	return
+}
+
+// RawToASN1 - converts a fixed length `r||s` signature sized by the curve of the key type to ASN.1 DER encoding
+func RawToASN1(raw []byte, kt shared.KeyType) (sig []byte, err error) {
+
+	size, err := scalarSize(kt)
+	if nil != err {
+		return nil, fmt.Errorf("ecdsa-rawtoasn1: %w", err)
+	}
+
+	if len(raw) != 2*size {
+		return nil, fmt.Errorf("ecdsa-rawtoasn1: invalid signature length %d, expected %d for %s", len(raw), 2*size, kt)
+	}
+
+	var b cryptobyte.Builder
+	b.AddASN1(asn1.SEQUENCE, func(b *cryptobyte.Builder) {
+		b.AddASN1BigInt(new(big.Int).SetBytes(raw[:size]))
+		b.AddASN1BigInt(new(big.Int).SetBytes(raw[size:]))
+	})
+
+	sig, err = b.Bytes()
+	if nil != err {
+		return nil, fmt.Errorf("ecdsa-rawtoasn1: %w", err)
+	}
+
+	return
+}
+
+// scalarSize - returns the size in bytes of `r` and `s` for the curve of the key type
+func scalarSize(kt shared.KeyType) (size int, err error) {
+
+	switch kt {
+	case shared.ECDSA256:
+		return 32, nil
+	case shared.ECDSA384:
+		return 48, nil
+	case shared.ECDSA521:
+		return 66, nil
+	}
+
+	return 0, fmt.Errorf("unsupported key type %s for ECDSA signatures", kt)
+}
+
+// parseASN1 - decodes an ASN.1 DER encoded ECDSA signature into its `r` and `s` components
+func parseASN1(sig []byte) (r, s []byte, err error) {
+
+	var inner cryptobyte.String
+	input := cryptobyte.String(sig)
+
+	if !input.ReadASN1(&inner, asn1.SEQUENCE) || !input.Empty() ||
+		!inner.ReadASN1Integer(&r) || !inner.ReadASN1Integer(&s) || !inner.Empty() {
+		return nil, nil, errors.New("invalid ASN.1 signature encoding")
+	}
+
+	return
+}
@@ -1,6 +1,7 @@
 package ec
 
 import (
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
@@ -9,35 +10,52 @@ import (
 	"github.com/svicknesh/key/v2/shared"
 )
 
-// Generate - generates a new RSA public/private key
-func Generate(kt shared.KeyType) (k *K, err error) {
-	k = new(K)
+// Generate - generates a new ECDSA public/private key
+func Generate(kt shared.KeyType, opts ...shared.Option) (k *K, err error) {
+	k = &K{lifetime: new(shared.Lifetime)}
+	o := shared.NewOptions(opts...)
+
+	var curve elliptic.Curve
+	var mask byte = 0xff
 
 	switch kt {
 	case shared.ECDSA256:
-		k.priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		curve = elliptic.P256()
 	case shared.ECDSA384:
-		k.priv, err = ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+		curve = elliptic.P384()
 	case shared.ECDSA521:
-		k.priv, err = ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+		curve = elliptic.P521()
+		mask = 0x01 // P-521 scalars only use the lowest bit of the first byte
 	default:
 		return nil, fmt.Errorf("ecdsa-generate: unsupported key type for ECDSA generation")
 	}
 
+	if o.IsDefaultRand() {
+		k.priv, err = ecdsa.GenerateKey(curve, rand.Reader)
+	} else {
+		// the standard library ignores custom readers, so the scalar is read from the reader directly
+		err = shared.SampleScalar(o.Rand, (curve.Params().BitSize+7)/8, mask, func(b []byte) (err error) {
+			k.priv, err = ecdsa.ParseRawPrivateKey(curve, b)
+			return
+		})
+	}
+
 	if nil != err {
 		return nil, fmt.Errorf("ecdsa-generate: error generating ECDSA key -> %w", err)
 	}
 
 	k.isPriv = true
+	k.lifetime.SetClock(o.Clock)
 	k.kt = kt
 
 	return
 }
 
-// New - converts a raw key interface into instance of ECDSA
-func New(rkey any) (k *K, err error) {
+// New - converts a raw key interface into instance of ECDSA, `shared.WithClock` sets the clock its validity window is checked against
+func New(rkey any, opts ...shared.Option) (k *K, err error) {
 
-	k = new(K)
+	k = &K{lifetime: new(shared.Lifetime)}
+	k.lifetime.SetClock(shared.NewOptions(opts...).Clock)
 
 	var crv string
 
@@ -67,3 +85,33 @@ func New(rkey any) (k *K, err error) {
 
 	return k, nil
 }
+
+// hashForLength - returns the SHA-2 function used by RFC 6979 for a digest of the given length
+func hashForLength(length int) (h crypto.Hash, err error) {
+
+	switch length {
+	case 32:
+		return crypto.SHA256, nil
+	case 48:
+		return crypto.SHA384, nil
+	case 64:
+		return crypto.SHA512, nil
+	}
+
+	return 0, fmt.Errorf("deterministic signing needs a 32, 48 or 64 byte digest, got %d bytes", length)
+}
+
+// curveFor - returns the curve used by the key type
+func curveFor(kt shared.KeyType) (curve elliptic.Curve, err error) {
+
+	switch kt {
+	case shared.ECDSA256:
+		return elliptic.P256(), nil
+	case shared.ECDSA384:
+		return elliptic.P384(), nil
+	case shared.ECDSA521:
+		return elliptic.P521(), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %s for ECDSA", kt)
+}
@@ -1,10 +1,14 @@
 package ed
 
 import (
+	"bytes"
 	"crypto/ed25519"
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"time"
 
 	"github.com/lestrrat-go/jwx/v3/jwk"
 	"github.com/svicknesh/key/v2/shared"
@@ -15,6 +19,9 @@ type K struct {
 	pub           ed25519.PublicKey
 	isPriv, isPub bool
 	kid           string
+	destroyed     bool
+
+	lifetime *shared.Lifetime // validity window and usage counter enforced by `Sign`, shared by copies of K
 }
 
 // Bytes - returns JSON encoded bytes of the key
@@ -25,6 +32,8 @@ func (k *K) Bytes() (bytes []byte, err error) {
 		in = k.priv
 	} else if k.isPub {
 		in = k.pub
+	} else if k.destroyed {
+		return nil, fmt.Errorf("ed25519-bytes: %w", shared.ErrKeyDestroyed)
 	} else {
 		return nil, fmt.Errorf("ed25519-bytes: neither public nor private key found")
 	}
@@ -44,6 +53,10 @@ func (k *K) Bytes() (bytes []byte, err error) {
 		jk.Set(jwk.KeyIDKey, k.kid)
 	}
 
+	if err = k.lifetime.WriteJWK(jk.Set); nil != err {
+		return nil, fmt.Errorf("ed25519-bytes: error setting validity -> %w", err)
+	}
+
 	return json.Marshal(jk)
 }
 
@@ -61,7 +74,14 @@ func (k *K) PublicKey() (kPub shared.Key, err error) {
 		return nil, errors.New("ed25519-publickey: no private key exists to extract public key")
 	}
 
-	return New(k.priv.Public())
+	pub, err := New(k.priv.Public())
+	if nil != err {
+		return nil, err
+	}
+	pub.kid = k.kid
+	pub.SetValidity(k.Validity()) // verifiers see the same validity window
+
+	return pub, nil
 }
 
 // PrivateKeyInstance - returns actual instance of private key of type
@@ -100,25 +120,117 @@ func (k *K) KeyType() (kt shared.KeyType) {
 	return shared.ED25519
 }
 
-// Sign - signs the given hashed data using the ED25519 private key
-func (k *K) Sign(hashed []byte) (signed []byte, err error) {
+// Sign - signs the given hashed data using the ED25519 private key, ED25519 signatures are deterministic so no options apply
+func (k *K) Sign(hashed []byte, opts ...shared.Option) (signed []byte, err error) {
+	return k.SignWithOptions(hashed, &ed25519.Options{})
+}
+
+// Verify - verifies the signed data of the given hashed data using the ED25519 public key
+func (k *K) Verify(signed []byte, hashed []byte) (ok bool) {
+	return k.VerifyErr(signed, hashed) == nil
+}
+
+// VerifyErr - verifies the signed data of the given hashed data using the ED25519 public key, returning the reason for failure
+func (k *K) VerifyErr(signed []byte, hashed []byte) (err error) {
+	return k.VerifyWithOptions(signed, hashed, &ed25519.Options{})
+}
+
+// SignWithOptions - signs the given data using the ED25519 variant selected by `opts` (see `PhOptions` and `CtxOptions`), Ed25519ph expects the SHA-512 digest of the message
+func (k *K) SignWithOptions(hashed []byte, opts *ed25519.Options) (signed []byte, err error) {
+
+	if k.destroyed {
+		return nil, fmt.Errorf("ed25519-sign: %w", shared.ErrKeyDestroyed)
+	}
 
 	if !k.isPriv {
 		return nil, fmt.Errorf("ed25519-sign: private key does not exist for signing data")
 	}
 
-	signed = ed25519.Sign(k.priv, hashed)
+	if err = checkOptions(opts); nil != err {
+		return nil, fmt.Errorf("ed25519-sign: %w", err)
+	}
+
+	if err = k.lifetime.Use(); nil != err {
+		return nil, fmt.Errorf("ed25519-sign: %w", err)
+	}
+	defer func() {
+		// a failed signature does not count against the usage limit
+		if nil != err {
+			k.lifetime.Refund()
+		}
+	}()
+
+	signed, err = k.priv.Sign(nil, hashed, opts)
+	if nil != err {
+		err = fmt.Errorf("ed25519-sign: ED25519 signature generation failed -> %w", err)
+	}
 
 	return
 }
 
-// Verify - verifies the signed data of the given hashed data using the ED25519 public key
-func (k *K) Verify(signed []byte, hashed []byte) (ok bool) {
-	if !k.isPub {
-		return
+// VerifyWithOptions - verifies the signed data of the given data using the ED25519 variant selected by `opts`, returning the reason for failure
+func (k *K) VerifyWithOptions(signed []byte, hashed []byte, opts *ed25519.Options) (err error) {
+
+	pub, ok := k.PublicKeyInstance().(ed25519.PublicKey) // private keys verify using their public half
+	if !ok {
+		return fmt.Errorf("ed25519-verify: %w", shared.ErrNoPublicKey)
+	}
+
+	if err = checkOptions(opts); nil != err {
+		return fmt.Errorf("ed25519-verify: %w", err)
+	}
+
+	if len(signed) != ed25519.SignatureSize {
+		return fmt.Errorf("ed25519-verify: %w: expected %d bytes, got %d", shared.ErrMalformedSignature, ed25519.SignatureSize, len(signed))
+	}
+
+	if err = ed25519.VerifyWithOptions(pub, hashed, signed, opts); nil != err {
+		return fmt.Errorf("ed25519-verify: %w", shared.ErrSignatureMismatch)
 	}
 
-	return ed25519.Verify(k.pub, hashed, signed)
+	return
+}
+
+// SignReaderWithOptions - signs the message read from `rd` using the ED25519 variant selected by `opts`, Ed25519ph hashes the message as it is read while the other variants read it into memory
+func (k *K) SignReaderWithOptions(rd io.Reader, opts *ed25519.Options) (signed []byte, err error) {
+
+	msg, err := readMessage(rd, opts)
+	if nil != err {
+		return nil, fmt.Errorf("ed25519-signreader: %w", err)
+	}
+
+	return k.SignWithOptions(msg, opts)
+}
+
+// VerifyReaderWithOptions - verifies the signed data of the message read from `rd` using the ED25519 variant selected by `opts`, returning the reason for failure
+func (k *K) VerifyReaderWithOptions(rd io.Reader, signed []byte, opts *ed25519.Options) (err error) {
+
+	msg, err := readMessage(rd, opts)
+	if nil != err {
+		return fmt.Errorf("ed25519-verifyreader: %w", err)
+	}
+
+	return k.VerifyWithOptions(signed, msg, opts)
+}
+
+// SignMessage - signs the given message using pure ED25519, no hashing is done by the caller
+func (k *K) SignMessage(msg []byte, opts ...shared.Option) (signed []byte, err error) {
+	return k.Sign(msg, opts...)
+}
+
+// VerifyMessage - verifies the signed data of the given message using the ED25519 public key
+func (k *K) VerifyMessage(msg []byte, signed []byte) (ok bool) {
+	return k.Verify(signed, msg)
+}
+
+// SignReader - signs the message read from `rd`, pure ED25519 needs the entire message so it is read into memory
+func (k *K) SignReader(rd io.Reader, opts ...shared.Option) (signed []byte, err error) {
+	return k.SignReaderWithOptions(rd, &ed25519.Options{})
+}
+
+// VerifyReader - verifies the signed data of the message read from `rd`, pure ED25519 needs the entire message so it is read into memory
+func (k *K) VerifyReader(rd io.Reader, signed []byte) (ok bool) {
+	return k.VerifyReaderWithOptions(rd, signed, &ed25519.Options{}) == nil
 }
 
 // MarshalJSON - marshals this Key into a JSON
@@ -136,3 +248,88 @@ func (k *K) SetKeyID(kid string) (err error) {
 func (k *K) GetKeyID() (kid string) {
 	return k.kid
 }
+
+// SetValidity - sets the window during which the key may sign and its maximum number of uses
+func (k *K) SetValidity(v shared.Validity) {
+	k.lifetime.SetValidity(v)
+}
+
+// Validity - returns the window during which the key may sign and its maximum number of uses
+func (k *K) Validity() (v shared.Validity) {
+	return k.lifetime.Validity()
+}
+
+// Uses - returns the number of signatures made with the key
+func (k *K) Uses() (uses uint64) {
+	return k.lifetime.Uses()
+}
+
+// SetExpiryWarning - calls `fn` from `Sign` when the key is used `within` the given duration of its expiry
+func (k *K) SetExpiryWarning(within time.Duration, fn shared.ExpiryWarning) {
+	k.lifetime.SetExpiryWarning(within, fn)
+}
+
+// Destroy - zeroes the private key and drops all key material, every later use of the key fails.
+// The private key shares memory with the `ed25519.PrivateKey` given to `New` or returned by `PrivateKeyInstance`, which is zeroed as well.
+func (k *K) Destroy() {
+	clear(k.priv)
+	k.priv, k.pub = nil, nil
+	k.isPriv, k.isPub = false, false
+	k.destroyed = true
+}
+
+// Equal - returns if `other` holds the same key material in constant time, both must be private or both public keys and the key ID is ignored
+func (k *K) Equal(other shared.Key) (ok bool) {
+	if nil == other || other.KeyType() != shared.ED25519 || k.isPriv != other.IsPrivateKey() {
+		return false
+	}
+
+	if !k.isPriv {
+		return k.PublicEqual(other)
+	}
+
+	priv, ok := other.PrivateKeyInstance().(ed25519.PrivateKey)
+
+	return ok && subtle.ConstantTimeCompare(k.priv, priv) == 1
+}
+
+// PublicEqual - returns if the public key of `other` matches the public key of this key in constant time, private keys compare using their public half
+func (k *K) PublicEqual(other shared.Key) (ok bool) {
+	if nil == other || other.KeyType() != shared.ED25519 {
+		return false
+	}
+
+	pub, ok1 := k.PublicKeyInstance().(ed25519.PublicKey)
+	pub2, ok2 := other.PublicKeyInstance().(ed25519.PublicKey)
+
+	return ok1 && ok2 && subtle.ConstantTimeCompare(pub, pub2) == 1
+}
+
+// Validate - checks the private key matches its embedded public key, and that the public key is a canonically encoded curve point that is not of small order
+func (k *K) Validate() (err error) {
+
+	if k.destroyed {
+		return fmt.Errorf("ed25519-validate: %w", shared.ErrKeyDestroyed)
+	}
+
+	pub, ok := k.PublicKeyInstance().(ed25519.PublicKey)
+	if !ok {
+		return fmt.Errorf("ed25519-validate: %w: neither public nor private key found", shared.ErrInvalidKey)
+	}
+
+	if k.isPriv {
+		if len(k.priv) != ed25519.PrivateKeySize {
+			return fmt.Errorf("ed25519-validate: %w: invalid private key length %d", shared.ErrInvalidKey, len(k.priv))
+		}
+
+		if !bytes.Equal(ed25519.NewKeyFromSeed(k.priv.Seed())[32:], pub) {
+			return fmt.Errorf("ed25519-validate: %w: public key does not match private key", shared.ErrInvalidKey)
+		}
+	}
+
+	if err = checkPublicKey(pub); nil != err {
+		return fmt.Errorf("ed25519-validate: %w: %w", shared.ErrInvalidKey, err)
+	}
+
+	return
+}
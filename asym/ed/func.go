@@ -1,29 +1,39 @@
 package ed
 
 import (
+	"bytes"
+	"crypto"
 	"crypto/ed25519"
-	"crypto/rand"
+	"crypto/sha512"
+	"errors"
 	"fmt"
+	"io"
+
+	"filippo.io/edwards25519"
+	"github.com/svicknesh/key/v2/shared"
 )
 
 // Generate - generates a new ED255 public/private key
-func Generate() (k *K, err error) {
-	k = new(K)
+func Generate(opts ...shared.Option) (k *K, err error) {
+	k = &K{lifetime: new(shared.Lifetime)}
+	o := shared.NewOptions(opts...)
 
-	_, k.priv, err = ed25519.GenerateKey(rand.Reader)
+	_, k.priv, err = ed25519.GenerateKey(o.Rand)
 	if nil != err {
 		return nil, fmt.Errorf("ed25519-generate: error generating ED25519 key -> %w", err)
 	}
 
 	k.isPriv = true
+	k.lifetime.SetClock(o.Clock)
 
 	return
 }
 
-// New - converts a raw key interface into instance of ED25519
-func New(rkey any) (k *K, err error) {
+// New - converts a raw key interface into instance of ED25519, `shared.WithClock` sets the clock its validity window is checked against
+func New(rkey any, opts ...shared.Option) (k *K, err error) {
 
-	k = new(K)
+	k = &K{lifetime: new(shared.Lifetime)}
+	k.lifetime.SetClock(shared.NewOptions(opts...).Clock)
 
 	switch kt := rkey.(type) {
 	case ed25519.PrivateKey:
@@ -38,3 +48,83 @@ func New(rkey any) (k *K, err error) {
 
 	return k, nil
 }
+
+// PhOptions - returns options selecting Ed25519ph (RFC 8032) with an optional context, the data signed must be the SHA-512 digest of the message
+func PhOptions(context string) (opts *ed25519.Options) {
+	return &ed25519.Options{Hash: crypto.SHA512, Context: context}
+}
+
+// CtxOptions - returns options selecting Ed25519ctx (RFC 8032) for domain separation, the context must not be empty
+// since options without a context select plain Ed25519
+func CtxOptions(context string) (opts *ed25519.Options, err error) {
+
+	if len(context) == 0 {
+		return nil, errors.New("ed25519-ctxoptions: Ed25519ctx requires a non-empty context")
+	}
+
+	return &ed25519.Options{Context: context}, nil
+}
+
+// checkOptions - makes sure the options select one of the RFC 8032 variants
+func checkOptions(opts *ed25519.Options) (err error) {
+
+	if nil == opts {
+		return errors.New("options must not be nil")
+	}
+
+	switch opts.Hash {
+	case crypto.Hash(0), crypto.SHA512:
+	default:
+		return fmt.Errorf("unsupported hash %v, expected SHA-512 for Ed25519ph", opts.Hash)
+	}
+
+	if len(opts.Context) > 255 {
+		return fmt.Errorf("context too long (%d bytes), maximum is 255", len(opts.Context))
+	}
+
+	return
+}
+
+// readMessage - returns the SHA-512 digest of the message for Ed25519ph, otherwise the entire message
+func readMessage(rd io.Reader, opts *ed25519.Options) (msg []byte, err error) {
+
+	if nil != opts && opts.Hash == crypto.SHA512 {
+		h := sha512.New()
+		if _, err = io.Copy(h, rd); nil != err {
+			return nil, fmt.Errorf("error reading message -> %w", err)
+		}
+		return h.Sum(nil), nil
+	}
+
+	msg, err = io.ReadAll(rd)
+	if nil != err {
+		return nil, fmt.Errorf("error reading message -> %w", err)
+	}
+
+	return
+}
+
+// checkPublicKey - checks the public key is a canonical encoding of a point on edwards25519 that is not of small order
+func checkPublicKey(pub ed25519.PublicKey) (err error) {
+
+	if len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key length %d", len(pub))
+	}
+
+	p, err := new(edwards25519.Point).SetBytes(pub)
+	if nil != err {
+		return errors.New("public key is not a point on the curve")
+	}
+
+	// `SetBytes` accepts non-canonical encodings, which encode the point again differently
+	if !bytes.Equal(p.Bytes(), pub) {
+		return errors.New("public key is not canonically encoded")
+	}
+
+	// multiplying by the cofactor 8 gives the identity only for points of small order
+	if p.MultByCofactor(p).Equal(edwards25519.NewIdentityPoint()) == 1 {
+		return errors.New("public key is of small order")
+	}
+
+	return
+}
@@ -1,24 +1,24 @@
 package r
 
 import (
-	"crypto/rand"
 	"crypto/rsa"
 	"fmt"
 
 	"github.com/svicknesh/key/v2/shared"
 )
 
-// Generate - generates a new RSA public/private key
-func Generate(kt shared.KeyType) (k *K, err error) {
-	k = new(K)
+// Generate - generates a new RSA public/private key, since Go 1.26 a custom source of randomness is only used with `GODEBUG=cryptocustomrand=1`
+func Generate(kt shared.KeyType, opts ...shared.Option) (k *K, err error) {
+	k = &K{lifetime: new(shared.Lifetime)}
+	o := shared.NewOptions(opts...)
 
 	switch kt {
 	case shared.RSA2048:
-		k.priv, err = rsa.GenerateKey(rand.Reader, 2048)
+		k.priv, err = rsa.GenerateKey(o.Rand, 2048)
 	case shared.RSA4096:
-		k.priv, err = rsa.GenerateKey(rand.Reader, 4096)
+		k.priv, err = rsa.GenerateKey(o.Rand, 4096)
 	case shared.RSA8192:
-		k.priv, err = rsa.GenerateKey(rand.Reader, 8192)
+		k.priv, err = rsa.GenerateKey(o.Rand, 8192)
 	default:
 		return nil, fmt.Errorf("rsa-generate: unsupported key type for RSA generation")
 	}
@@ -29,14 +29,16 @@ func Generate(kt shared.KeyType) (k *K, err error) {
 
 	k.isPriv = true
 	k.kt = kt
+	k.lifetime.SetClock(o.Clock)
 
 	return
 }
 
-// New - converts a raw key interface into instance of RSA
-func New(rkey any) (k *K, err error) {
+// New - converts a raw key interface into instance of RSA, `shared.WithClock` sets the clock its validity window is checked against
+func New(rkey any, opts ...shared.Option) (k *K, err error) {
 
-	k = new(K)
+	k = &K{lifetime: new(shared.Lifetime)}
+	k.lifetime.SetClock(shared.NewOptions(opts...).Clock)
 
 	var size int
 	switch kt := rkey.(type) {
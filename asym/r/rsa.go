@@ -1,12 +1,16 @@
 package r
 
 import (
+	"bytes"
 	"crypto"
-	"crypto/rand"
 	"crypto/rsa"
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"math/big"
+	"time"
 
 	"github.com/lestrrat-go/jwx/v3/jwk"
 	"github.com/svicknesh/key/v2/shared"
@@ -18,6 +22,9 @@ type K struct {
 	pub           *rsa.PublicKey
 	isPriv, isPub bool
 	kid           string
+	destroyed     bool
+
+	lifetime *shared.Lifetime // validity window and usage counter enforced by `Sign`, shared by copies of K
 }
 
 // Bytes - returns JSON encoded bytes of the key
@@ -28,6 +35,8 @@ func (k *K) Bytes() (bytes []byte, err error) {
 		in = k.priv
 	} else if k.isPub {
 		in = k.pub
+	} else if k.destroyed {
+		return nil, fmt.Errorf("rsa-bytes: %w", shared.ErrKeyDestroyed)
 	} else {
 		return nil, fmt.Errorf("rsa-bytes: neither public nor private key found")
 	}
@@ -47,6 +56,10 @@ func (k *K) Bytes() (bytes []byte, err error) {
 		jk.Set(jwk.KeyIDKey, k.kid)
 	}
 
+	if err = k.lifetime.WriteJWK(jk.Set); nil != err {
+		return nil, fmt.Errorf("rsa-bytes: error setting validity -> %w", err)
+	}
+
 	return json.Marshal(jk)
 }
 
@@ -64,7 +77,14 @@ func (k *K) PublicKey() (kPub shared.Key, err error) {
 		return nil, errors.New("rsa-publickey: no private key exists to extract public key")
 	}
 
-	return New(k.priv.Public())
+	pub, err := New(k.priv.Public())
+	if nil != err {
+		return nil, err
+	}
+	pub.kid = k.kid
+	pub.SetValidity(k.Validity()) // verifiers see the same validity window
+
+	return pub, nil
 }
 
 // PrivateKeyInstance - returns actual instance of private key of type
@@ -103,15 +123,80 @@ func (k *K) KeyType() (kt shared.KeyType) {
 }
 
 // Sign - signs the given hashed data using the RSA private key (using RSA PSS)
-func (k *K) Sign(hashed []byte) (signed []byte, err error) {
+func (k *K) Sign(hashed []byte, opts ...shared.Option) (signed []byte, err error) {
+	return k.sign(crypto.SHA256, hashed, shared.NewOptions(opts...))
+}
+
+// Verify - verifies the signed data of the given hashed data using the RSA public key (using RSA PSS)
+func (k *K) Verify(signed []byte, hashed []byte) (ok bool) {
+	return k.VerifyErr(signed, hashed) == nil
+}
+
+// VerifyErr - verifies the signed data of the given hashed data using the RSA public key (using RSA PSS), returning the reason for failure
+func (k *K) VerifyErr(signed []byte, hashed []byte) (err error) {
+	return k.verify(crypto.SHA256, signed, hashed)
+}
+
+// SignMessage - hashes the given message with the digest matching the modulus and signs it using the RSA private key (using RSA PSS)
+func (k *K) SignMessage(msg []byte, opts ...shared.Option) (signed []byte, err error) {
+	return k.SignReader(bytes.NewReader(msg), opts...)
+}
+
+// VerifyMessage - hashes the given message with the digest matching the modulus and verifies the signed data using the RSA public key (using RSA PSS)
+func (k *K) VerifyMessage(msg []byte, signed []byte) (ok bool) {
+	return k.VerifyReader(bytes.NewReader(msg), signed)
+}
+
+// SignReader - hashes the message read from `rd` with the digest matching the modulus and signs it using the RSA private key (using RSA PSS)
+func (k *K) SignReader(rd io.Reader, opts ...shared.Option) (signed []byte, err error) {
+
+	digest, err := shared.Digest(k.kt, rd)
+	if nil != err {
+		return nil, fmt.Errorf("rsa-signreader: %w", err)
+	}
+
+	return k.sign(k.kt.Hash(), digest, shared.NewOptions(opts...))
+}
+
+// VerifyReader - hashes the message read from `rd` with the digest matching the modulus and verifies the signed data using the RSA public key (using RSA PSS)
+func (k *K) VerifyReader(rd io.Reader, signed []byte) (ok bool) {
+
+	digest, err := shared.Digest(k.kt, rd)
+	if nil != err {
+		return
+	}
+
+	return k.verify(k.kt.Hash(), signed, digest) == nil
+}
+
+// sign - signs the hashed data, which must be the output of `hash`, using RSA PSS
+func (k *K) sign(hash crypto.Hash, hashed []byte, o *shared.Options) (signed []byte, err error) {
+
+	if k.destroyed {
+		return nil, fmt.Errorf("rsa-sign: %w", shared.ErrKeyDestroyed)
+	}
 
 	if !k.isPriv {
 		return nil, fmt.Errorf("rsa-sign: private key does not exist for signing data")
 	}
 
+	if err = o.CheckFIPSOptions(); nil != err {
+		return nil, fmt.Errorf("rsa-sign: %w", err)
+	}
+
+	if err = k.lifetime.Use(); nil != err {
+		return nil, fmt.Errorf("rsa-sign: %w", err)
+	}
+	defer func() {
+		// a failed signature does not count against the usage limit
+		if nil != err {
+			k.lifetime.Refund()
+		}
+	}()
+
 	//signed, err = rsa.SignPKCS1v15(rand.Reader, k.priv, crypto.SHA256, hashed)
-	pssOpts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
-	signed, err = rsa.SignPSS(rand.Reader, k.priv, crypto.SHA256, hashed, pssOpts)
+	pssOpts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}
+	signed, err = rsa.SignPSS(o.Rand, k.priv, hash, hashed, pssOpts)
 	if nil != err {
 		err = fmt.Errorf("rsa-sign: RSA signature generation failed -> %w", err)
 	}
@@ -119,18 +204,23 @@ func (k *K) Sign(hashed []byte) (signed []byte, err error) {
 	return
 }
 
-// Verify - verifies the signed data of the given hashed data using the RSA public key (using RSA PSS)
-func (k *K) Verify(signed []byte, hashed []byte) (ok bool) {
+// verify - verifies the signed data of the hashed data, which must be the output of `hash`, using RSA PSS
+func (k *K) verify(hash crypto.Hash, signed []byte, hashed []byte) (err error) {
 
-	if !k.isPub {
-		return
+	pub, ok := k.PublicKeyInstance().(*rsa.PublicKey) // private keys verify using their public half
+	if !ok {
+		return fmt.Errorf("rsa-verify: %w", shared.ErrNoPublicKey)
+	}
+
+	if len(signed) != pub.Size() {
+		return fmt.Errorf("rsa-verify: %w: expected %d bytes, got %d", shared.ErrMalformedSignature, pub.Size(), len(signed))
 	}
 
 	//err = rsa.VerifyPKCS1v15(k.pub, crypto.SHA256, hashed, signed)
-	pssOpts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256}
-	err := rsa.VerifyPSS(k.pub, crypto.SHA256, hashed, signed, pssOpts)
-	if nil == err {
-		ok = true // if there are no errors from VerifyPSS, all is good with the verification
+	pssOpts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: hash}
+	err = rsa.VerifyPSS(pub, hash, hashed, signed, pssOpts)
+	if nil != err {
+		return fmt.Errorf("rsa-verify: %w -> %w", shared.ErrSignatureMismatch, err)
 	}
 
 	return
@@ -151,3 +241,116 @@ func (k *K) SetKeyID(kid string) (err error) {
 func (k *K) GetKeyID() (kid string) {
 	return k.kid
 }
+
+// SetValidity - sets the window during which the key may sign and its maximum number of uses
+func (k *K) SetValidity(v shared.Validity) {
+	k.lifetime.SetValidity(v)
+}
+
+// Validity - returns the window during which the key may sign and its maximum number of uses
+func (k *K) Validity() (v shared.Validity) {
+	return k.lifetime.Validity()
+}
+
+// Uses - returns the number of signatures made with the key
+func (k *K) Uses() (uses uint64) {
+	return k.lifetime.Uses()
+}
+
+// SetExpiryWarning - calls `fn` from `Sign` when the key is used `within` the given duration of its expiry
+func (k *K) SetExpiryWarning(within time.Duration, fn shared.ExpiryWarning) {
+	k.lifetime.SetExpiryWarning(within, fn)
+}
+
+// Destroy - zeroes the private exponent, primes and CRT values and drops all key material, every later use of the key fails.
+// The standard library keeps its own copy of the private key inside `rsa.PrivateKey` which cannot be zeroed, it is released for garbage collection instead.
+func (k *K) Destroy() {
+	if nil != k.priv {
+		ints := append([]*big.Int{k.priv.D, k.priv.Precomputed.Dp, k.priv.Precomputed.Dq, k.priv.Precomputed.Qinv}, k.priv.Primes...)
+		for _, crt := range k.priv.Precomputed.CRTValues {
+			ints = append(ints, crt.Exp, crt.Coeff, crt.R)
+		}
+		for _, i := range ints {
+			if nil != i {
+				clear(i.Bits())
+				i.SetInt64(0)
+			}
+		}
+	}
+	k.priv, k.pub = nil, nil
+	k.isPriv, k.isPub = false, false
+	k.destroyed = true
+}
+
+// Equal - returns if `other` holds the same key material in constant time, both must be private or both public keys and the key ID is ignored
+func (k *K) Equal(other shared.Key) (ok bool) {
+	if nil == other || other.KeyType() != k.kt || k.isPriv != other.IsPrivateKey() {
+		return false
+	}
+
+	if !k.isPriv {
+		return k.PublicEqual(other)
+	}
+
+	priv, ok := other.PrivateKeyInstance().(*rsa.PrivateKey)
+	if !ok || nil == priv.D || !k.PublicEqual(other) {
+		return false
+	}
+
+	// the modulus is public, the private exponents are padded to its size so only their contents are compared
+	size := k.priv.Size()
+	if priv.D.BitLen() > size*8 {
+		return false
+	}
+
+	d1 := k.priv.D.FillBytes(make([]byte, size))
+	d2 := priv.D.FillBytes(make([]byte, size))
+	defer clear(d1)
+	defer clear(d2)
+
+	return subtle.ConstantTimeCompare(d1, d2) == 1
+}
+
+// PublicEqual - returns if the public key of `other` matches the public key of this key in constant time, private keys compare using their public half
+func (k *K) PublicEqual(other shared.Key) (ok bool) {
+	if nil == other || other.KeyType() != k.kt {
+		return false
+	}
+
+	pub1, ok1 := k.PublicKeyInstance().(*rsa.PublicKey)
+	pub2, ok2 := other.PublicKeyInstance().(*rsa.PublicKey)
+	if !ok1 || !ok2 || pub1.Size() != pub2.Size() {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(pub1.N.Bytes(), pub2.N.Bytes()) == 1 && pub1.E == pub2.E
+}
+
+// Validate - checks the modulus and public exponent, and for private keys the consistency of the primes and exponents using `rsa.PrivateKey.Validate`
+func (k *K) Validate() (err error) {
+
+	if k.destroyed {
+		return fmt.Errorf("rsa-validate: %w", shared.ErrKeyDestroyed)
+	}
+
+	pub, ok := k.PublicKeyInstance().(*rsa.PublicKey)
+	if !ok || nil == pub || nil == pub.N {
+		return fmt.Errorf("rsa-validate: %w: neither public nor private key found", shared.ErrInvalidKey)
+	}
+
+	if pub.N.Bit(0) == 0 {
+		return fmt.Errorf("rsa-validate: %w: modulus is even", shared.ErrInvalidKey)
+	}
+
+	if pub.E < 3 || pub.E&1 == 0 {
+		return fmt.Errorf("rsa-validate: %w: invalid public exponent %d", shared.ErrInvalidKey, pub.E)
+	}
+
+	if k.isPriv {
+		if err = k.priv.Validate(); nil != err {
+			return fmt.Errorf("rsa-validate: %w: %w", shared.ErrInvalidKey, err)
+		}
+	}
+
+	return
+}
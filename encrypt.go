@@ -0,0 +1,163 @@
+package key
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/svicknesh/enum2str"
+	"github.com/svicknesh/key/v2/shared"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Cipher - authenticated encryption used by `Encrypt`
+type Cipher uint8
+
+const (
+	// AES256GCM - AES-256-GCM with a random 96 bit nonce, a sender should encrypt at most 2^32 messages for the same recipient
+	AES256GCM Cipher = iota + 1
+
+	// XChaCha20Poly1305 - XChaCha20-Poly1305 with a random 192 bit nonce, not approved in FIPS 140-3 mode
+	XChaCha20Poly1305
+)
+
+// encryptVersion - first byte of every ciphertext, changes when the format or key derivation changes
+const encryptVersion = 1
+
+// encryptHeaderSize - version and cipher
+const encryptHeaderSize = 2
+
+// ErrDecrypt - returned when a ciphertext cannot be decrypted, because it was altered or is meant for a different key pair
+var ErrDecrypt = errors.New("decryption failed")
+
+// String - returns string name for a given cipher
+func (c Cipher) String() (str string) {
+	return enum2str.String(c, "unknown", "aes256gcm", "xchacha20poly1305")
+}
+
+// Encrypt - encrypts the plaintext for the peer using a key derived from the shared secret of `kx` and `kxPeer`, `aad` is authenticated but not encrypted.
+// The key is bound to the public keys of the sender and the recipient in that order, so only the peer decrypts, using its private key exchange and the public key exchange of the sender.
+// The ciphertext starts with a version and the cipher used, followed by a random nonce, the encrypted plaintext and the authentication tag.
+func Encrypt(kx, kxPeer KeyExchange, plaintext, aad []byte, c Cipher) (ciphertext []byte, err error) {
+
+	aead, err := encryptionAEAD(kx, kxPeer, c, true)
+	if nil != err {
+		return nil, fmt.Errorf("encrypt: %w", err)
+	}
+
+	header := []byte{encryptVersion, byte(c)}
+	ciphertext = append(make([]byte, 0, encryptHeaderSize+aead.NonceSize()+len(plaintext)+aead.Overhead()), header...)
+
+	if c == AES256GCM {
+		// the nonce is generated by the AEAD and prepended to the ciphertext
+		return aead.Seal(ciphertext, nil, plaintext, slices.Concat(header, aad)), nil
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); nil != err {
+		return nil, fmt.Errorf("encrypt: error generating nonce -> %w", err)
+	}
+	ciphertext = append(ciphertext, nonce...)
+
+	return aead.Seal(ciphertext, nonce, plaintext, slices.Concat(header, aad)), nil
+}
+
+// Decrypt - decrypts a ciphertext from `Encrypt` sent by the owner of `kxPeer`, failing with `ErrDecrypt` if it was altered, meant for another key pair or `aad` differs
+func Decrypt(kx, kxPeer KeyExchange, ciphertext, aad []byte) (plaintext []byte, err error) {
+
+	if len(ciphertext) < encryptHeaderSize {
+		return nil, fmt.Errorf("decrypt: %w, ciphertext too short", ErrDecrypt)
+	}
+
+	if ciphertext[0] != encryptVersion {
+		return nil, fmt.Errorf("decrypt: unsupported version %d", ciphertext[0])
+	}
+
+	c := Cipher(ciphertext[1])
+	aead, err := encryptionAEAD(kx, kxPeer, c, false)
+	if nil != err {
+		return nil, fmt.Errorf("decrypt: %w", err)
+	}
+
+	header, body := ciphertext[:encryptHeaderSize], ciphertext[encryptHeaderSize:]
+	if len(body) < aead.NonceSize()+aead.Overhead() {
+		return nil, fmt.Errorf("decrypt: %w, ciphertext too short", ErrDecrypt)
+	}
+
+	if c == AES256GCM {
+		plaintext, err = aead.Open(nil, nil, body, slices.Concat(header, aad))
+	} else {
+		plaintext, err = aead.Open(nil, body[:aead.NonceSize()], body[aead.NonceSize():], slices.Concat(header, aad))
+	}
+	if nil != err {
+		return nil, fmt.Errorf("decrypt: %w", ErrDecrypt)
+	}
+
+	return
+}
+
+// encryptionAEAD - derives the key for the cipher from the shared secret with HKDF-SHA256, binding it to the cipher and the public keys of the sender and recipient.
+// `sending` is true when `kx` is the sender and `kxPeer` the recipient, false when it is the other way round.
+func encryptionAEAD(kx, kxPeer KeyExchange, c Cipher, sending bool) (aead cipher.AEAD, err error) {
+
+	switch c {
+	case AES256GCM:
+	case XChaCha20Poly1305:
+		if err = shared.CheckFIPS("XChaCha20-Poly1305 encryption"); nil != err {
+			return
+		}
+	default:
+		return nil, fmt.Errorf("unsupported cipher %d", c)
+	}
+
+	if nil == kx || nil == kxPeer || !kx.IsPrivateKey() {
+		return nil, errors.New("a private and a peer key exchange are required")
+	}
+
+	secret, err := kx.SharedSecret(kxPeer)
+	if nil != err {
+		return
+	}
+	defer clear(secret)
+
+	sender, recipient := kx, kxPeer
+	if !sending {
+		sender, recipient = kxPeer, kx
+	}
+
+	k, err := hkdf.Key(sha256.New, secret, nil, encryptionInfo(sender, recipient, c), 32)
+	if nil != err {
+		return
+	}
+	defer clear(k)
+
+	if c == XChaCha20Poly1305 {
+		return chacha20poly1305.NewX(k)
+	}
+
+	block, err := aes.NewCipher(k)
+	if nil != err {
+		return
+	}
+
+	return cipher.NewGCMWithRandomNonce(block)
+}
+
+// encryptionInfo - HKDF info binding the key to the format version, cipher, key exchange type and the public keys of the sender and recipient.
+// The order of the public keys gives each direction its own key, so a ciphertext cannot be reflected back to its sender.
+func encryptionInfo(sender, recipient KeyExchange, c Cipher) (info string) {
+
+	b := append([]byte("svicknesh/key encrypt"), encryptVersion, byte(c), byte(sender.KeyType()))
+	for _, pub := range [][]byte{sender.PublicKeyInstance(), recipient.PublicKeyInstance()} {
+		b = binary.BigEndian.AppendUint16(b, uint16(len(pub)))
+		b = append(b, pub...)
+	}
+
+	return string(b)
+}
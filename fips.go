@@ -0,0 +1,33 @@
+package key
+
+import "github.com/svicknesh/key/v2/shared"
+
+// FIPSMode - returns if the Go Cryptographic Module runs in FIPS 140-3 mode, see `shared.FIPSMode`.
+//
+// In FIPS 140-3 mode key and key exchange types that are not approved (`CURVE25519`) cannot be generated, parsed or used,
+// keys cannot be generated using `WithRand`, and keys cannot be derived from seeds, passwords or mnemonics. These fail with an error wrapping `ErrNotFIPSApproved`.
+func FIPSMode() (enabled bool) {
+	return shared.FIPSMode()
+}
+
+// AvailableKeyTypes - returns the key types that can be used in the current mode
+func AvailableKeyTypes() (kts []KeyType) {
+	for kt := ED25519; kt <= RSA8192; kt++ {
+		if nil == shared.CheckFIPSKey(kt) {
+			kts = append(kts, kt)
+		}
+	}
+
+	return
+}
+
+// AvailableKeyXTypes - returns the key exchange types that can be used in the current mode
+func AvailableKeyXTypes() (kxts []KeyXType) {
+	for kxt := CURVE25519; kxt <= ECDH521; kxt++ {
+		if nil == shared.CheckFIPSKeyX(kxt) {
+			kxts = append(kxts, kxt)
+		}
+	}
+
+	return
+}
@@ -36,6 +36,23 @@ const (
 	RSA8192 = shared.RSA8192
 )
 
+const (
+	// HS256 - generate a 256 bit symmetric key for HMAC using SHA-256
+	HS256 = shared.HS256
+
+	// HS384 - generate a 384 bit symmetric key for HMAC using SHA-384
+	HS384 = shared.HS384
+
+	// HS512 - generate a 512 bit symmetric key for HMAC using SHA-512
+	HS512 = shared.HS512
+
+	// A128GCM - generate a 128 bit symmetric key for AES-GCM
+	A128GCM = shared.A128GCM
+
+	// A256GCM - generate a 256 bit symmetric key for AES-GCM
+	A256GCM = shared.A256GCM
+)
+
 const (
 	// CURVE25519 - generates a Curve25519 key exchange
 	CURVE25519 = shared.CURVE25519
@@ -50,24 +67,30 @@ const (
 	ECDH521 = shared.ECDH521
 )
 
-// GenerateKey - generates a new key
-func GenerateKey(kt shared.KeyType) (k shared.Key, err error) {
+// GenerateKey - generates a new key, options such as `WithRand` change how it is generated
+func GenerateKey(kt shared.KeyType, opts ...Option) (k shared.Key, err error) {
+
+	o := shared.NewOptions(opts...)
+
+	if err = errors.Join(shared.CheckFIPSKey(kt), o.CheckFIPSOptions(), o.Policy.CheckKey(kt, shared.OpGenerate)); nil != err {
+		return nil, fmt.Errorf("generatekey: %w", err)
+	}
 
 	switch kt {
 	case ED25519:
-		k, err = ed.Generate()
+		k, err = ed.Generate(opts...)
 	case ECDSA256:
-		k, err = ec.Generate(shared.ECDSA256)
+		k, err = ec.Generate(shared.ECDSA256, opts...)
 	case ECDSA384:
-		k, err = ec.Generate(shared.ECDSA384)
+		k, err = ec.Generate(shared.ECDSA384, opts...)
 	case ECDSA521:
-		k, err = ec.Generate(shared.ECDSA521)
+		k, err = ec.Generate(shared.ECDSA521, opts...)
 	case RSA2048:
-		k, err = r.Generate(shared.RSA2048)
+		k, err = r.Generate(shared.RSA2048, opts...)
 	case RSA4096:
-		k, err = r.Generate(shared.RSA4096)
+		k, err = r.Generate(shared.RSA4096, opts...)
 	case RSA8192:
-		k, err = r.Generate(shared.RSA8192)
+		k, err = r.Generate(shared.RSA8192, opts...)
 
 	default:
 		err = errors.New("unsupported key type given for asymetric generation")
@@ -80,18 +103,24 @@ func GenerateKey(kt shared.KeyType) (k shared.Key, err error) {
 	return k, nil
 }
 
-// GenerateKeyExchange - generates a new key exchange public/private
-func GenerateKeyExchange(kxt shared.KeyXType) (kx shared.KeyExchange, err error) {
+// GenerateKeyExchange - generates a new key exchange public/private, options such as `WithRand` change how it is generated
+func GenerateKeyExchange(kxt shared.KeyXType, opts ...Option) (kx shared.KeyExchange, err error) {
+
+	o := shared.NewOptions(opts...)
+
+	if err = errors.Join(shared.CheckFIPSKeyX(kxt), o.CheckFIPSOptions(), o.Policy.CheckKeyX(kxt, shared.OpGenerate)); nil != err {
+		return nil, fmt.Errorf("generatekeyexchange: %w", err)
+	}
 
 	switch kxt {
 	case CURVE25519:
-		kx, err = crv.Generate()
+		kx, err = crv.Generate(opts...)
 	case ECDH256:
-		kx, err = ecdhc.Generate(shared.ECDH256)
+		kx, err = ecdhc.Generate(shared.ECDH256, opts...)
 	case ECDH384:
-		kx, err = ecdhc.Generate(shared.ECDH384)
+		kx, err = ecdhc.Generate(shared.ECDH384, opts...)
 	case ECDH521:
-		kx, err = ecdhc.Generate(shared.ECDH521)
+		kx, err = ecdhc.Generate(shared.ECDH521, opts...)
 	default:
 		err = errors.New("unsupported key type given for exchange generation")
 	}
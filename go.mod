@@ -3,6 +3,8 @@ module github.com/svicknesh/key/v2
 go 1.26.0
 
 require (
+	filippo.io/edwards25519 v1.2.0
+	filippo.io/nistec v0.0.4
 	github.com/lestrrat-go/jwx/v3 v3.1.1
 	github.com/svicknesh/enum2str v1.0.2
 	golang.org/x/crypto v0.50.0
@@ -0,0 +1,383 @@
+// Package hd derives hierarchical deterministic keys with SLIP-0010 for ED25519 and ECDSA256 (NIST P-256) only.
+// secp256k1, and so BIP-32, is not supported since it is not a key type of this library, other key types return `ErrUnsupportedCurve`.
+package hd
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"filippo.io/nistec"
+	"github.com/svicknesh/key/v2/asym/ec"
+	"github.com/svicknesh/key/v2/asym/ed"
+	"github.com/svicknesh/key/v2/shared"
+)
+
+// HardenedOffset - child indexes at or above this value are hardened, written as `i'` or `iH` in a path
+const HardenedOffset uint32 = 0x80000000
+
+// ErrHardenedOnly - returned when non-hardened derivation is requested for ED25519, which SLIP-0010 does not define
+var ErrHardenedOnly = errors.New("ED25519 only supports hardened derivation")
+
+// ErrUnsupportedCurve - returned for key types other than ED25519 and ECDSA256, including secp256k1 (BIP-32) which this package does not implement
+var ErrUnsupportedCurve = errors.New("unsupported curve, only ED25519 and ECDSA256 (NIST P-256) are supported, secp256k1 (BIP-32) is not")
+
+// Node - extended key (key material and chain code) at a position in a SLIP-0010 derivation tree
+type Node struct {
+	kt        shared.KeyType
+	priv      []byte // 32 byte private key, nil for public nodes
+	pub       []byte // 33 byte compressed public key, only used by ECDSA256
+	chainCode []byte
+	depth     uint8
+	index     uint32
+}
+
+// NewMaster - returns the master node of a SLIP-0010 tree for ED25519 or ECDSA256 (NIST P-256) from a 16 to 64 byte seed
+func NewMaster(kt shared.KeyType, seed []byte) (n *Node, err error) {
+
+	if err = shared.CheckFIPS("SLIP-0010 hierarchical key derivation"); nil != err {
+		return nil, fmt.Errorf("hd-newmaster: %w", err)
+	}
+
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("hd-newmaster: invalid seed length %d, expected 16 to 64 bytes", len(seed))
+	}
+
+	var curveKey string
+	switch kt {
+	case shared.ED25519:
+		curveKey = "ed25519 seed"
+	case shared.ECDSA256:
+		curveKey = "Nist256p1 seed"
+	default:
+		return nil, fmt.Errorf("hd-newmaster: key type %s -> %w", kt, ErrUnsupportedCurve)
+	}
+
+	data := seed
+	for {
+		il, ir := hmacSplit([]byte(curveKey), data)
+
+		// P-256 master keys must be a valid scalar, otherwise the HMAC output is hashed again
+		if kt == shared.ECDSA256 && !validScalar(il) {
+			data = append(il, ir...)
+			continue
+		}
+
+		n = &Node{kt: kt, priv: il, chainCode: ir}
+		break
+	}
+
+	if kt == shared.ECDSA256 {
+		if n.pub, err = publicFromPrivate(n.priv); nil != err {
+			return nil, fmt.Errorf("hd-newmaster: %w", err)
+		}
+	}
+
+	return
+}
+
+// DeriveKey - derives the key at `path` (for example `m/44'/0'/3'`) from a seed, see `NewMaster`
+func DeriveKey(kt shared.KeyType, seed []byte, path string) (k shared.Key, err error) {
+
+	n, err := NewMaster(kt, seed)
+	if nil != err {
+		return nil, err
+	}
+
+	n, err = n.Derive(path)
+	if nil != err {
+		return nil, err
+	}
+
+	return n.Key()
+}
+
+// Derive - derives the node at `path`, relative paths start from this node while paths starting with `m` require this to be a master node
+func (n *Node) Derive(path string) (child *Node, err error) {
+
+	indexes, absolute, err := ParsePath(path)
+	if nil != err {
+		return nil, fmt.Errorf("hd-derive: %w", err)
+	}
+
+	if absolute && n.depth != 0 {
+		return nil, fmt.Errorf("hd-derive: absolute path %q given for a node at depth %d", path, n.depth)
+	}
+
+	child = n
+	for _, i := range indexes {
+		if child, err = child.Child(i); nil != err {
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// Child - derives the direct child at `index`, add `HardenedOffset` for hardened children
+func (n *Node) Child(index uint32) (child *Node, err error) {
+
+	if n.depth == 255 {
+		return nil, errors.New("hd-child: maximum depth reached")
+	}
+
+	hardened := index >= HardenedOffset
+	if n.kt == shared.ED25519 && !hardened {
+		return nil, fmt.Errorf("hd-child: %w", ErrHardenedOnly)
+	}
+	if hardened && n.priv == nil {
+		return nil, errors.New("hd-child: hardened derivation needs a private node")
+	}
+
+	var idx [4]byte
+	binary.BigEndian.PutUint32(idx[:], index)
+
+	var data []byte
+	if hardened {
+		data = append(append([]byte{0}, n.priv...), idx[:]...)
+	} else {
+		data = append(bytes.Clone(n.pub), idx[:]...)
+	}
+
+	child = &Node{kt: n.kt, depth: n.depth + 1, index: index}
+
+	for {
+		il, ir := hmacSplit(n.chainCode, data)
+		child.chainCode = ir
+
+		if n.kt == shared.ED25519 {
+			child.priv = il
+			return
+		}
+
+		if n.priv != nil {
+			child.priv, err = addScalars(il, n.priv)
+			if nil == err {
+				child.pub, err = publicFromPrivate(child.priv)
+			}
+		} else {
+			child.pub, err = addPoints(il, n.pub)
+		}
+
+		// SLIP-0010 retries with the chain code when the result is not a valid key
+		if errors.Is(err, errInvalidChild) {
+			data = append(append([]byte{1}, ir...), idx[:]...)
+			continue
+		}
+
+		if nil != err {
+			return nil, fmt.Errorf("hd-child: %w", err)
+		}
+
+		return
+	}
+}
+
+// Neuter - returns the extended public node, which derives non-hardened ECDSA256 public children without the private key
+func (n *Node) Neuter() (pub *Node, err error) {
+
+	if n.kt != shared.ECDSA256 {
+		return nil, fmt.Errorf("hd-neuter: %w", ErrHardenedOnly)
+	}
+
+	pub = &Node{kt: n.kt, pub: bytes.Clone(n.pub), chainCode: bytes.Clone(n.chainCode), depth: n.depth, index: n.index}
+
+	return
+}
+
+// Key - returns the key of this node, private nodes return a private key and public nodes a public key
+func (n *Node) Key() (k shared.Key, err error) {
+
+	switch n.kt {
+	case shared.ED25519:
+		return ed.New(ed25519.NewKeyFromSeed(n.priv))
+
+	case shared.ECDSA256:
+		if n.priv != nil {
+			priv, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), n.priv)
+			if nil != err {
+				return nil, fmt.Errorf("hd-key: %w", err)
+			}
+			return ec.New(priv)
+		}
+
+		x, y := elliptic.UnmarshalCompressed(elliptic.P256(), n.pub)
+		if nil == x {
+			return nil, errors.New("hd-key: invalid public key")
+		}
+
+		pub, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), uncompressed(x, y))
+		if nil != err {
+			return nil, fmt.Errorf("hd-key: %w", err)
+		}
+		return ec.New(pub)
+	}
+
+	return nil, fmt.Errorf("hd-key: key type %s -> %w", n.kt, ErrUnsupportedCurve)
+}
+
+// KeyType - returns the key type of this node
+func (n *Node) KeyType() (kt shared.KeyType) {
+	return n.kt
+}
+
+// IsPrivate - returns if this node holds a private key
+func (n *Node) IsPrivate() (p bool) {
+	return n.priv != nil
+}
+
+// ChainCode - returns the chain code of this node
+func (n *Node) ChainCode() (chainCode []byte) {
+	return bytes.Clone(n.chainCode)
+}
+
+// PrivateKeyBytes - returns the raw 32 byte private key of this node, nil for public nodes
+func (n *Node) PrivateKeyBytes() (priv []byte) {
+	return bytes.Clone(n.priv)
+}
+
+// PublicKeyBytes - returns the SLIP-0010 serialized public key, `0x00` followed by the key for ED25519 and the compressed point for ECDSA256
+func (n *Node) PublicKeyBytes() (pub []byte) {
+	if n.kt == shared.ED25519 {
+		return append([]byte{0}, ed25519.NewKeyFromSeed(n.priv).Public().(ed25519.PublicKey)...)
+	}
+
+	return bytes.Clone(n.pub)
+}
+
+// Depth - returns the depth of this node, the master node is 0
+func (n *Node) Depth() (depth uint8) {
+	return n.depth
+}
+
+// Index - returns the child index of this node
+func (n *Node) Index() (index uint32) {
+	return n.index
+}
+
+// ParsePath - parses a derivation path such as `m/44'/0'/3'`, `absolute` reports if it starts from the master node
+func ParsePath(path string) (indexes []uint32, absolute bool, err error) {
+
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if parts[0] == "m" || parts[0] == "M" {
+		absolute = true
+		parts = parts[1:]
+	}
+
+	for _, p := range parts {
+		if p == "" {
+			return nil, false, fmt.Errorf("empty component in path %q", path)
+		}
+
+		var offset uint32
+		if strings.HasSuffix(p, "'") || strings.HasSuffix(p, "H") || strings.HasSuffix(p, "h") {
+			offset = HardenedOffset
+			p = p[:len(p)-1]
+		}
+
+		i, err := strconv.ParseUint(p, 10, 32)
+		if nil != err || uint32(i) >= HardenedOffset {
+			return nil, false, fmt.Errorf("invalid index %q in path %q", p, path)
+		}
+
+		indexes = append(indexes, uint32(i)+offset)
+	}
+
+	return
+}
+
+// errInvalidChild - SLIP-0010 result that is not a valid key, derivation continues with the next HMAC input
+var errInvalidChild = errors.New("invalid child key")
+
+// hmacSplit - returns both halves of HMAC-SHA512
+func hmacSplit(key, data []byte) (il, ir []byte) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	i := mac.Sum(nil)
+	return i[:32], i[32:]
+}
+
+// validScalar - returns if the bytes are a non-zero P-256 scalar below the group order
+func validScalar(b []byte) (ok bool) {
+	s := new(big.Int).SetBytes(b)
+	return s.Sign() > 0 && s.Cmp(elliptic.P256().Params().N) < 0
+}
+
+// addScalars - returns (il + priv) mod n for P-256
+func addScalars(il, priv []byte) (child []byte, err error) {
+
+	n := elliptic.P256().Params().N
+
+	t := new(big.Int).SetBytes(il)
+	if t.Cmp(n) >= 0 {
+		return nil, errInvalidChild
+	}
+
+	t.Add(t, new(big.Int).SetBytes(priv))
+	t.Mod(t, n)
+	if t.Sign() == 0 {
+		return nil, errInvalidChild
+	}
+
+	return t.FillBytes(make([]byte, 32)), nil
+}
+
+// addPoints - returns the compressed point(il) + pub for P-256, using the constant time point arithmetic of `filippo.io/nistec`
+func addPoints(il, pub []byte) (child []byte, err error) {
+
+	if !validScalar(il) {
+		return nil, errInvalidChild
+	}
+
+	p, err := nistec.NewP256Point().SetBytes(pub)
+	if nil != err {
+		return nil, errors.New("invalid parent public key")
+	}
+
+	q, err := nistec.NewP256Point().ScalarBaseMult(il)
+	if nil != err {
+		return nil, err
+	}
+
+	// the point at infinity is encoded as a single zero byte
+	if child = q.Add(q, p).BytesCompressed(); len(child) == 1 {
+		return nil, errInvalidChild
+	}
+
+	return
+}
+
+// publicFromPrivate - returns the compressed P-256 public key of a private scalar
+func publicFromPrivate(priv []byte) (pub []byte, err error) {
+
+	k, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), priv)
+	if nil != err {
+		return nil, err
+	}
+
+	u, err := k.PublicKey.Bytes()
+	if nil != err {
+		return nil, err
+	}
+
+	// compressed form is the parity of y followed by x
+	return append([]byte{2 | u[64]&1}, u[1:33]...), nil
+}
+
+// uncompressed - returns the uncompressed encoding of a P-256 point
+func uncompressed(x, y *big.Int) (b []byte) {
+	b = make([]byte, 65)
+	b[0] = 4
+	x.FillBytes(b[1:33])
+	y.FillBytes(b[33:])
+	return
+}
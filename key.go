@@ -8,6 +8,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
+	"time"
 
 	"github.com/lestrrat-go/jwx/v3/jwk"
 	"github.com/svicknesh/key/v2/asym/ec"
@@ -24,24 +27,96 @@ type Key = shared.Key // create an alias so users don't need to understand the l
 // Key - alias of `shared.KeyExchange`
 type KeyExchange = shared.KeyExchange
 
-/*
-// use this for extracting specific information only
-type j struct {
-	KeyID string `json:"kid"`
+// KeyType - alias of `shared.KeyType`
+type KeyType = shared.KeyType
+
+// KeyXType - alias of `shared.KeyXType`
+type KeyXType = shared.KeyXType
+
+// SymmetricKey - alias of `shared.SymmetricKey`
+type SymmetricKey = shared.SymmetricKey
+
+// SymKeyType - alias of `shared.SymKeyType`
+type SymKeyType = shared.SymKeyType
+
+// Option - alias of `shared.Option`
+type Option = shared.Option
+
+// Policy - alias of `shared.Policy`
+type Policy = shared.Policy
+
+// Rule - alias of `shared.Rule`
+type Rule = shared.Rule
+
+// Validity - alias of `shared.Validity`
+type Validity = shared.Validity
+
+// ValidityError - alias of `shared.ValidityError`
+type ValidityError = shared.ValidityError
+
+// PolicyError - alias of `shared.PolicyError`
+type PolicyError = shared.PolicyError
+
+// Operation - alias of `shared.Operation`
+type Operation = shared.Operation
+
+const (
+	// OpGenerate - alias of `shared.OpGenerate`
+	OpGenerate = shared.OpGenerate
+
+	// OpImport - alias of `shared.OpImport`
+	OpImport = shared.OpImport
+)
+
+var (
+	// ErrNoPublicKey - alias of `shared.ErrNoPublicKey`
+	ErrNoPublicKey = shared.ErrNoPublicKey
+
+	// ErrMalformedSignature - alias of `shared.ErrMalformedSignature`
+	ErrMalformedSignature = shared.ErrMalformedSignature
+
+	// ErrSignatureMismatch - alias of `shared.ErrSignatureMismatch`
+	ErrSignatureMismatch = shared.ErrSignatureMismatch
+
+	// ErrKeyDestroyed - alias of `shared.ErrKeyDestroyed`
+	ErrKeyDestroyed = shared.ErrKeyDestroyed
+
+	// ErrInvalidKey - alias of `shared.ErrInvalidKey`
+	ErrInvalidKey = shared.ErrInvalidKey
+
+	// ErrPolicyViolation - alias of `shared.ErrPolicyViolation`
+	ErrPolicyViolation = shared.ErrPolicyViolation
+
+	// ErrNotFIPSApproved - alias of `shared.ErrNotFIPSApproved`
+	ErrNotFIPSApproved = shared.ErrNotFIPSApproved
+
+	// ErrKeyNotYetValid - alias of `shared.ErrKeyNotYetValid`
+	ErrKeyNotYetValid = shared.ErrKeyNotYetValid
+
+	// ErrKeyExpired - alias of `shared.ErrKeyExpired`
+	ErrKeyExpired = shared.ErrKeyExpired
+
+	// ErrKeyUsageExceeded - alias of `shared.ErrKeyUsageExceeded`
+	ErrKeyUsageExceeded = shared.ErrKeyUsageExceeded
+)
+
+// jwkMeta - members of a JWK kept by the key besides the key material
+type jwkMeta struct {
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"` // must match the type of symmetric keys
+	NotBefore int64  `json:"nbf"`
+	NotAfter  int64  `json:"exp"`
 }
-*/
 
-// NewKeyFromBytes - returns new instance of key from given JWK bytes
-func NewKeyFromBytes(jwkBytes []byte) (k Key, err error) {
+// NewKeyFromBytes - returns new instance of key from given JWK bytes, `WithStrict` validates the key before it is returned and `WithClock` sets the clock its validity window is checked against
+func NewKeyFromBytes(jwkBytes []byte, opts ...Option) (k Key, err error) {
 
-	/*
-		// we need to do a double json unmarshal to get the key id, if I find a better way later, I will make the necessary change
-		jkid := new(j)
-		err = json.Unmarshal(jwkBytes, jkid)
-		if err != nil {
-			return nil, fmt.Errorf("newkeyfrombytes: JWK key unmarshal error -> %w", err)
-		}
-	*/
+	// we need to do a double json unmarshal to get the key id and validity, if I find a better way later, I will make the necessary change
+	meta := new(jwkMeta)
+	err = json.Unmarshal(jwkBytes, meta)
+	if err != nil {
+		return nil, fmt.Errorf("newkeyfrombytes: JWK key unmarshal error -> %w", err)
+	}
 
 	var rkey any
 
@@ -52,31 +127,95 @@ func NewKeyFromBytes(jwkBytes []byte) (k Key, err error) {
 
 	switch rkey.(type) {
 	case ed25519.PrivateKey, ed25519.PublicKey:
-		k, err = ed.New(rkey)
+		k, err = ed.New(rkey, opts...)
 	case *ecdsa.PrivateKey, *ecdsa.PublicKey:
-		k, err = ec.New(rkey)
+		k, err = ec.New(rkey, opts...)
 	case *rsa.PrivateKey, *rsa.PublicKey:
-		k, err = r.New(rkey)
+		k, err = r.New(rkey, opts...)
+	case []byte:
+		err = errors.New("symmetric JWK, use `NewSymmetricKeyFromBytes`")
 	default:
 		err = fmt.Errorf("newkeyfrombytes: unsupported JWK key type %T", rkey)
 	}
 
-	//k.SetKeyID(jkid.KeyID) // sets the key identifier if one is given
-
 	if err != nil {
-		err = fmt.Errorf("newkeyfrombytes: %w", err)
+		return nil, fmt.Errorf("newkeyfrombytes: %w", err)
+	}
+
+	k.SetKeyID(meta.KeyID) // sets the key identifier if one is given, otherwise it is generated from the key
+
+	var v shared.Validity
+	if meta.NotBefore != 0 {
+		v.NotBefore = time.Unix(meta.NotBefore, 0)
+	}
+	if meta.NotAfter != 0 {
+		v.NotAfter = time.Unix(meta.NotAfter, 0)
+	}
+	k.SetValidity(v)
+
+	o := shared.NewOptions(opts...)
+
+	if err = errors.Join(shared.CheckFIPSKey(k.KeyType()), o.Policy.CheckKey(k.KeyType(), shared.OpImport)); nil != err {
+		return nil, fmt.Errorf("newkeyfrombytes: %w", err)
+	}
+
+	if o.Strict {
+		if err = k.Validate(); nil != err {
+			return nil, fmt.Errorf("newkeyfrombytes: %w", err)
+		}
+
+		if err = checkJWKPublic(jwkBytes, k); nil != err {
+			return nil, fmt.Errorf("newkeyfrombytes: %w", err)
+		}
 	}
 
 	return
 }
 
-// NewKeyFromStr - returns new instance of key from a given JWK string
-func NewKeyFromStr(jwkStr string) (k Key, err error) {
-	return NewKeyFromBytes([]byte(jwkStr))
+// NewKeyFromStr - returns new instance of key from a given JWK string, `WithStrict` validates the key before it is returned
+func NewKeyFromStr(jwkStr string, opts ...Option) (k Key, err error) {
+	return NewKeyFromBytes([]byte(jwkStr), opts...)
+}
+
+// checkJWKPublic - checks the public members of a private JWK match the public key derived from its private key
+func checkJWKPublic(jwkBytes []byte, k Key) (err error) {
+
+	if !k.IsPrivateKey() {
+		return
+	}
+
+	kb, err := k.Bytes()
+	if nil != err {
+		return
+	}
+
+	var given, derived map[string]any
+	if err = json.Unmarshal(jwkBytes, &given); nil != err {
+		return
+	}
+	if err = json.Unmarshal(kb, &derived); nil != err {
+		return
+	}
+
+	for _, member := range []string{"crv", "x", "y", "n", "e"} {
+		v, ok := given[member]
+		if !ok {
+			continue
+		}
+
+		// base64url members may be given with padding
+		s, _ := v.(string)
+		d, _ := derived[member].(string)
+		if strings.TrimRight(s, "=") != d {
+			return fmt.Errorf("%w: JWK member %q does not match the private key", shared.ErrInvalidKey, member)
+		}
+	}
+
+	return
 }
 
-// NewFromRawKey - returns new instance of key from given raw key
-func NewFromRawKey(rawKey any) (k Key, err error) {
+// NewFromRawKey - returns new instance of key from given raw key, `WithStrict` validates the key before it is returned
+func NewFromRawKey(rawKey any, opts ...Option) (k Key, err error) {
 
 	// the reason we take this approach is `NewKeyFromBytes` already does the key type checking, its not the best move to repeat that code here
 	jk, err := jwk.Import(rawKey)
@@ -89,7 +228,7 @@ func NewFromRawKey(rawKey any) (k Key, err error) {
 		return nil, fmt.Errorf("newfromrawkey: error marshaling -> %w", err)
 	}
 
-	k, err = NewKeyFromBytes(bytes)
+	k, err = NewKeyFromBytes(bytes, opts...)
 	if nil != err {
 		return nil, fmt.Errorf("newfromrawkey: %w", err)
 	}
@@ -97,8 +236,8 @@ func NewFromRawKey(rawKey any) (k Key, err error) {
 	return
 }
 
-// NewKXFromBytes - returns new instance of key exchange from given bytes
-func NewKXFromBytes(kxBytes []byte) (kx KeyExchange, err error) {
+// NewKXFromBytes - returns new instance of key exchange from given bytes, `WithStrict` validates the key exchange before it is returned
+func NewKXFromBytes(kxBytes []byte, opts ...Option) (kx KeyExchange, err error) {
 
 	if len(kxBytes) == 0 {
 		return nil, errors.New("newkxfrombytes: empty input")
@@ -110,23 +249,42 @@ func NewKXFromBytes(kxBytes []byte) (kx KeyExchange, err error) {
 		kx, err = crv.New(kxBytes)
 	case ecdhc.TypeECDHPriv256, ecdhc.TypeECDHPub256, ecdhc.TypeECDHPriv384, ecdhc.TypeECDHPub384, ecdhc.TypeECDHPriv521, ecdhc.TypeECDHPub521:
 		kx, err = ecdhc.New(kxBytes)
+	default:
+		err = fmt.Errorf("unknown key exchange type identifier %d", kxBytes[0])
 	}
 
 	if err != nil {
-		err = fmt.Errorf("newkxfrombytes: %w", err)
+		return nil, fmt.Errorf("newkxfrombytes: %w", err)
+	}
+
+	o := shared.NewOptions(opts...)
+
+	if err = errors.Join(shared.CheckFIPSKeyX(kx.KeyType()), o.Policy.CheckKeyX(kx.KeyType(), shared.OpImport)); nil != err {
+		return nil, fmt.Errorf("newkxfrombytes: %w", err)
+	}
+
+	if o.Strict {
+		if err = kx.Validate(); nil != err {
+			return nil, fmt.Errorf("newkxfrombytes: %w", err)
+		}
 	}
 
 	return
 }
 
-// NewKXFromStr - returns new instance of key exchange from a given string
-func NewKXFromStr(kxStr string) (kx KeyExchange, err error) {
+// NewKXFromStr - returns new instance of key exchange from a given string, `WithStrict` validates the key exchange before it is returned
+func NewKXFromStr(kxStr string, opts ...Option) (kx KeyExchange, err error) {
 	kxBytes, err := base64.URLEncoding.DecodeString(kxStr)
 	if err != nil {
 		return nil, fmt.Errorf("newkxfromstr: %w", err)
 	}
 
-	return NewKXFromBytes(kxBytes)
+	return NewKXFromBytes(kxBytes, opts...)
+}
+
+// WithRand - reads randomness from `rd` instead of `crypto/rand.Reader`, see `shared.WithRand`
+func WithRand(rd io.Reader) (opt Option) {
+	return shared.WithRand(rd)
 }
 
 // GetKeyType - returns proper key type given its name
@@ -138,3 +296,23 @@ func GetKeyType(name string) (kty shared.KeyType) {
 func GetKeyXType(name string) (kxty shared.KeyXType) {
 	return shared.GetKeyXType(name)
 }
+
+// GetSymKeyType - returns proper symmetric key type given its name
+func GetSymKeyType(name string) (st shared.SymKeyType) {
+	return shared.GetSymKeyType(name)
+}
+
+// WithStrict - validates keys and key exchanges when parsing, see `shared.WithStrict`
+func WithStrict() (opt Option) {
+	return shared.WithStrict()
+}
+
+// WithPolicy - denies generating or parsing key and key exchange types that violate the policy, see `shared.WithPolicy`
+func WithPolicy(p *Policy) (opt Option) {
+	return shared.WithPolicy(p)
+}
+
+// WithClock - generated and parsed keys check their validity window against `now` when signing, see `shared.WithClock`
+func WithClock(now func() time.Time) (opt Option) {
+	return shared.WithClock(now)
+}
@@ -0,0 +1,544 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/svicknesh/key/v2"
+	"github.com/svicknesh/key/v2/shared"
+)
+
+const (
+	metaFile    = "keystore.json" // KDF parameters and check value of the keystore
+	entryExt    = ".entry"        // extension of encrypted entries
+	fileVersion = 1
+
+	// KEKSize - size in bytes of the wrapping key, entries are encrypted with AES-256-GCM
+	KEKSize = 32
+
+	// maxKIDSize - longest `kid` whose encoded file name stays within common file system limits
+	maxKIDSize = 180
+
+	checkPlaintext = "svicknesh/key keystore"
+	checkAAD       = "keystore-check"
+)
+
+// fileMeta - contents of the keystore metadata file
+type fileMeta struct {
+	Version int    `json:"version"`
+	KDF     string `json:"kdf"` // "argon2id", "scrypt" or "none" when a wrapping key is given
+	Salt    []byte `json:"salt,omitempty"`
+	Time    uint32 `json:"time,omitempty"`
+	Memory  uint32 `json:"memory,omitempty"`
+	Threads uint8  `json:"threads,omitempty"`
+	N       int    `json:"n,omitempty"`
+	R       int    `json:"r,omitempty"`
+	P       int    `json:"p,omitempty"`
+	Check   []byte `json:"check"` // proves the passphrase or wrapping key is correct when the keystore is opened
+}
+
+// entryHeader - unencrypted details of an entry, authenticated as additional data so they cannot be swapped between entries
+type entryHeader struct {
+	Version int       `json:"version"`
+	KID     string    `json:"kid"`
+	Kind    string    `json:"kind"`
+	Type    string    `json:"type"`
+	Created time.Time `json:"created"`
+}
+
+// fileEntry - contents of an entry file
+type fileEntry struct {
+	entryHeader
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// FileStore - keystore keeping each key or key exchange in its own encrypted file within a directory only accessible by its owner
+type FileStore struct {
+	mu   sync.RWMutex
+	dir  string
+	aead cipher.AEAD
+}
+
+// NewFileStore - opens the keystore in `dir` with a master passphrase, creating it with `params` if it does not exist.
+// An existing keystore keeps the parameters it was created with.
+func NewFileStore(dir string, passphrase []byte, params key.PasswordParams) (s *FileStore, err error) {
+
+	s, err = openFileStore(dir, func(meta *fileMeta, create bool) (kek []byte, err error) {
+
+		if create {
+			switch params.KDF {
+			case key.Argon2id:
+				meta.KDF, meta.Time, meta.Memory, meta.Threads = "argon2id", params.Time, params.Memory, params.Threads
+			case key.Scrypt:
+				meta.KDF, meta.N, meta.R, meta.P = "scrypt", params.N, params.R, params.P
+			default:
+				return nil, fmt.Errorf("unknown password derivation function %d", params.KDF)
+			}
+			meta.Salt = make([]byte, key.MinSaltSize)
+			if _, err = rand.Read(meta.Salt); nil != err {
+				return
+			}
+		}
+
+		stored := key.PasswordParams{Time: meta.Time, Memory: meta.Memory, Threads: meta.Threads, N: meta.N, R: meta.R, P: meta.P}
+		switch meta.KDF {
+		case "argon2id":
+			stored.KDF = key.Argon2id
+		case "scrypt":
+			stored.KDF = key.Scrypt
+		case "none":
+			return nil, errors.New("keystore was created with a wrapping key, not a passphrase")
+		default:
+			return nil, fmt.Errorf("unknown password derivation function %q", meta.KDF)
+		}
+
+		return key.StretchPassword(passphrase, meta.Salt, stored)
+	})
+	if nil != err {
+		return nil, fmt.Errorf("keystore-newfilestore: %w", err)
+	}
+
+	return
+}
+
+// NewFileStoreWithKey - opens the keystore in `dir` with a `KEKSize` byte wrapping key, creating it if it does not exist
+func NewFileStoreWithKey(dir string, kek []byte) (s *FileStore, err error) {
+
+	if len(kek) != KEKSize {
+		return nil, fmt.Errorf("keystore-newfilestorewithkey: wrapping key must be %d bytes, got %d", KEKSize, len(kek))
+	}
+
+	s, err = openFileStore(dir, func(meta *fileMeta, create bool) ([]byte, error) {
+		if create {
+			meta.KDF = "none"
+		}
+		if meta.KDF != "none" {
+			return nil, errors.New("keystore was created with a passphrase, not a wrapping key")
+		}
+		return slices.Clone(kek), nil
+	})
+	if nil != err {
+		return nil, fmt.Errorf("keystore-newfilestorewithkey: %w", err)
+	}
+
+	return
+}
+
+// openFileStore - creates or checks the directory and metadata, `deriveKEK` fills in the metadata of a new keystore and returns the wrapping key
+func openFileStore(dir string, deriveKEK func(meta *fileMeta, create bool) (kek []byte, err error)) (s *FileStore, err error) {
+
+	if err = os.MkdirAll(dir, 0o700); nil != err {
+		return
+	}
+
+	fi, err := os.Stat(dir)
+	if nil != err {
+		return
+	}
+	if err = checkPermissions(dir, fi); nil != err {
+		return
+	}
+
+	s = &FileStore{dir: dir}
+	meta := new(fileMeta)
+	metaPath := filepath.Join(dir, metaFile)
+
+	metaBytes, err := s.readFile(metaPath)
+	create := errors.Is(err, fs.ErrNotExist)
+	switch {
+	case create:
+		meta.Version = fileVersion
+	case nil != err:
+		return nil, err
+	default:
+		if err = json.Unmarshal(metaBytes, meta); nil != err {
+			return nil, fmt.Errorf("error reading %s -> %w", metaFile, err)
+		}
+		if meta.Version != fileVersion {
+			return nil, fmt.Errorf("unsupported keystore version %d", meta.Version)
+		}
+	}
+
+	kek, err := deriveKEK(meta, create)
+	if nil != err {
+		return nil, err
+	}
+	s.aead, err = newAEAD(kek)
+	clear(kek)
+	if nil != err {
+		return nil, err
+	}
+
+	if create {
+		if meta.Check, err = s.seal([]byte(checkPlaintext), []byte(checkAAD)); nil != err {
+			return nil, err
+		}
+		if metaBytes, err = json.MarshalIndent(meta, "", "  "); nil != err {
+			return nil, err
+		}
+		if err = s.writeFile(metaPath, metaBytes); nil != err {
+			return nil, err
+		}
+		return
+	}
+
+	check, err := s.open(meta.Check, []byte(checkAAD))
+	if nil != err || string(check) != checkPlaintext {
+		return nil, ErrWrongKey
+	}
+
+	return
+}
+
+// Get - decrypts and returns the entry with the given `kid`
+func (s *FileStore) Get(kid string) (e Entry, err error) {
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if e, err = s.get(kid); nil != err {
+		return Entry{}, fmt.Errorf("keystore-get: %w", err)
+	}
+
+	return
+}
+
+// Put - encrypts and stores the entry, replacing any entry with the same `kid`, and returns the `kid` it was stored under
+func (s *FileStore) Put(e Entry) (kid string, err error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if kid, err = s.put(e); nil != err {
+		return "", fmt.Errorf("keystore-put: %w", err)
+	}
+
+	return
+}
+
+// Rotate - replaces the entry with a newly generated key or key exchange of the same type under the same `kid`, the old key material is destroyed
+func (s *FileStore) Rotate(kid string, opts ...shared.Option) (e Entry, err error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, err := s.get(kid)
+	if nil != err {
+		return Entry{}, fmt.Errorf("keystore-rotate: %w", err)
+	}
+	defer old.destroy()
+
+	if e, err = old.regenerate(opts...); nil != err {
+		return Entry{}, fmt.Errorf("keystore-rotate: %w", err)
+	}
+
+	if _, err = s.put(e); nil != err {
+		e.destroy()
+		return Entry{}, fmt.Errorf("keystore-rotate: %w", err)
+	}
+
+	return
+}
+
+// get - decrypts an entry, the caller must hold the lock
+func (s *FileStore) get(kid string) (e Entry, err error) {
+
+	path, err := s.entryPath(kid)
+	if nil != err {
+		return
+	}
+
+	fe, info, err := s.readEntry(path)
+	if nil != err {
+		return
+	}
+	if info.KID != kid {
+		// the file was renamed, the stored ID is authenticated while the file name is not
+		return Entry{}, fmt.Errorf("entry file for %q holds %q", kid, info.KID)
+	}
+
+	aad, err := json.Marshal(fe.entryHeader)
+	if nil != err {
+		return
+	}
+
+	material, err := s.open(append(fe.Nonce, fe.Ciphertext...), aad)
+	if nil != err {
+		return Entry{}, fmt.Errorf("error decrypting %q -> %w", kid, err)
+	}
+	defer clear(material)
+
+	return decodeEntry(info, material)
+}
+
+// put - encrypts and writes an entry, the caller must hold the write lock
+func (s *FileStore) put(e Entry) (kid string, err error) {
+
+	info, material, err := encodeEntry(e, time.Now())
+	if nil != err {
+		return
+	}
+	defer clear(material)
+
+	path, err := s.entryPath(info.KID)
+	if nil != err {
+		return
+	}
+
+	fe := fileEntry{entryHeader: entryHeader{Version: fileVersion, KID: info.KID, Kind: info.Kind.String(), Created: info.Created}}
+	if info.Kind == KindKey {
+		fe.Type = info.KeyType.String()
+	} else {
+		fe.Type = info.KeyXType.String()
+	}
+
+	aad, err := json.Marshal(fe.entryHeader)
+	if nil != err {
+		return
+	}
+
+	sealed, err := s.seal(material, aad)
+	if nil != err {
+		return
+	}
+	ns := s.aead.NonceSize()
+	fe.Nonce, fe.Ciphertext = sealed[:ns], sealed[ns:]
+
+	b, err := json.Marshal(fe)
+	if nil != err {
+		return
+	}
+
+	if err = s.writeFile(path, b); nil != err {
+		return
+	}
+
+	return info.KID, nil
+}
+
+// List - returns the details of every entry sorted by `kid`, without decrypting them
+func (s *FileStore) List() (infos []Info, err error) {
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	des, err := os.ReadDir(s.dir)
+	if nil != err {
+		return nil, fmt.Errorf("keystore-list: %w", err)
+	}
+
+	for _, de := range des {
+		if !de.Type().IsRegular() || !strings.HasSuffix(de.Name(), entryExt) {
+			continue
+		}
+
+		_, info, err := s.readEntry(filepath.Join(s.dir, de.Name()))
+		if nil != err {
+			return nil, fmt.Errorf("keystore-list: %w", err)
+		}
+		infos = append(infos, info)
+	}
+
+	slices.SortFunc(infos, func(a, b Info) int { return strings.Compare(a.KID, b.KID) })
+
+	return
+}
+
+// Delete - removes the entry with the given `kid`
+func (s *FileStore) Delete(kid string) (err error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path, err := s.entryPath(kid)
+	if nil != err {
+		return fmt.Errorf("keystore-delete: %w", err)
+	}
+
+	if err = os.Remove(path); nil != err {
+		if errors.Is(err, fs.ErrNotExist) {
+			err = fmt.Errorf("%w %q", ErrNotFound, kid)
+		}
+		return fmt.Errorf("keystore-delete: %w", err)
+	}
+
+	return syncDir(s.dir)
+}
+
+// entryPath - returns the file an entry is kept in, the `kid` is encoded so it cannot escape the directory
+func (s *FileStore) entryPath(kid string) (path string, err error) {
+
+	if kid == "" || len(kid) > maxKIDSize {
+		return "", fmt.Errorf("key id must be between 1 and %d bytes", maxKIDSize)
+	}
+
+	return filepath.Join(s.dir, base64.RawURLEncoding.EncodeToString([]byte(kid))+entryExt), nil
+}
+
+// readEntry - reads an entry file and its unencrypted details
+func (s *FileStore) readEntry(path string) (fe *fileEntry, info Info, err error) {
+
+	b, err := s.readFile(path)
+	if nil != err {
+		if errors.Is(err, fs.ErrNotExist) {
+			kid, _ := base64.RawURLEncoding.DecodeString(strings.TrimSuffix(filepath.Base(path), entryExt))
+			err = fmt.Errorf("%w %q", ErrNotFound, kid)
+		}
+		return
+	}
+
+	fe = new(fileEntry)
+	if err = json.Unmarshal(b, fe); nil != err {
+		return nil, Info{}, fmt.Errorf("error reading %s -> %w", filepath.Base(path), err)
+	}
+	if fe.Version != fileVersion {
+		return nil, Info{}, fmt.Errorf("unsupported entry version %d in %s", fe.Version, filepath.Base(path))
+	}
+
+	info = Info{KID: fe.KID, Created: fe.Created}
+	switch fe.Kind {
+	case KindKey.String():
+		info.Kind, info.KeyType = KindKey, shared.GetKeyType(fe.Type)
+	case KindKeyExchange.String():
+		info.Kind, info.KeyXType = KindKeyExchange, shared.GetKeyXType(fe.Type)
+	default:
+		return nil, Info{}, fmt.Errorf("unknown entry kind %q in %s", fe.Kind, filepath.Base(path))
+	}
+
+	return
+}
+
+// readFile - reads a file after making sure only its owner can access it
+func (s *FileStore) readFile(path string) (b []byte, err error) {
+
+	f, err := os.Open(path)
+	if nil != err {
+		return
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if nil != err {
+		return
+	}
+	if err = checkPermissions(path, fi); nil != err {
+		return
+	}
+
+	b = make([]byte, fi.Size())
+	_, err = f.ReadAt(b, 0)
+
+	return
+}
+
+// writeFile - replaces a file atomically by writing a temporary file in the same directory and renaming it
+func (s *FileStore) writeFile(path string, b []byte) (err error) {
+
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if nil != err {
+		return
+	}
+	defer func() {
+		if nil != err {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	// `CreateTemp` already uses 0600, this makes sure the umask or platform did not change it
+	if err = tmp.Chmod(0o600); nil != err {
+		return
+	}
+	if _, err = tmp.Write(b); nil != err {
+		return
+	}
+	if err = tmp.Sync(); nil != err {
+		return
+	}
+	if err = tmp.Close(); nil != err {
+		return
+	}
+	if err = os.Rename(tmp.Name(), path); nil != err {
+		return
+	}
+
+	return syncDir(s.dir)
+}
+
+// seal - encrypts with a random nonce, returning the nonce followed by the ciphertext
+func (s *FileStore) seal(plaintext, aad []byte) (sealed []byte, err error) {
+
+	nonce := make([]byte, s.aead.NonceSize(), s.aead.NonceSize()+len(plaintext)+s.aead.Overhead())
+	if _, err = rand.Read(nonce); nil != err {
+		return
+	}
+
+	return s.aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// open - decrypts the output of `seal`
+func (s *FileStore) open(sealed, aad []byte) (plaintext []byte, err error) {
+
+	ns := s.aead.NonceSize()
+	if len(sealed) < ns {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	return s.aead.Open(nil, sealed[:ns], sealed[ns:], aad)
+}
+
+// newAEAD - returns AES-256-GCM with the given key
+func newAEAD(kek []byte) (aead cipher.AEAD, err error) {
+
+	block, err := aes.NewCipher(kek)
+	if nil != err {
+		return
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// checkPermissions - refuses files and directories that group or other users can access, Windows permissions are not checked
+func checkPermissions(path string, fi fs.FileInfo) (err error) {
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	if perm := fi.Mode().Perm(); perm&0o077 != 0 {
+		return fmt.Errorf("%w %#o on %s", ErrInsecurePermissions, perm, path)
+	}
+
+	return
+}
+
+// syncDir - flushes a directory so a rename or removal survives a crash
+func syncDir(dir string) (err error) {
+
+	if runtime.GOOS == "windows" {
+		// directories cannot be opened for syncing on Windows
+		return
+	}
+
+	d, err := os.Open(dir)
+	if nil != err {
+		return
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
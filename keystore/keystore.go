@@ -0,0 +1,191 @@
+package keystore
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/svicknesh/enum2str"
+	"github.com/svicknesh/key/v2"
+	"github.com/svicknesh/key/v2/shared"
+)
+
+var (
+	// ErrNotFound - returned when no entry has the requested `kid`
+	ErrNotFound = errors.New("key not found")
+
+	// ErrWrongKey - returned when the passphrase or wrapping key does not open the keystore
+	ErrWrongKey = errors.New("wrong passphrase or wrapping key")
+
+	// ErrInsecurePermissions - returned when the keystore directory or one of its files can be accessed by other users
+	ErrInsecurePermissions = errors.New("insecure file permissions")
+)
+
+// KeyStore - storage for keys and key exchanges indexed by `kid`, implementations must be safe for concurrent use.
+// `keystoretest.Run` checks an implementation behaves the same as the ones in this package.
+type KeyStore interface {
+	Get(kid string) (e Entry, err error)                           // returns `ErrNotFound` when there is no entry
+	Put(e Entry) (kid string, err error)                           // replaces any entry with the same `kid`
+	List() (infos []Info, err error)                               // sorted by `kid`
+	Delete(kid string) (err error)                                 // returns `ErrNotFound` when there is no entry
+	Rotate(kid string, opts ...shared.Option) (e Entry, err error) // replaces the entry with a new key of the same type
+}
+
+// Kind - whether an entry holds a key or a key exchange
+type Kind uint8
+
+const (
+	// KindKey - entry holds a `shared.Key`
+	KindKey Kind = iota + 1
+
+	// KindKeyExchange - entry holds a `shared.KeyExchange`
+	KindKeyExchange
+)
+
+// String - returns string name for a given kind
+func (kd Kind) String() (str string) {
+	return enum2str.String(kd, "unknown", "key", "keyexchange")
+}
+
+// Entry - key or key exchange kept in a keystore, exactly one of `Key` or `KeyExchange` is set
+type Entry struct {
+	KID         string // defaults to the key ID of a key or `KeyExchangeID` of a key exchange when stored
+	Key         shared.Key
+	KeyExchange shared.KeyExchange
+}
+
+// Info - details of a stored entry that are available without decrypting it
+type Info struct {
+	KID      string
+	Kind     Kind
+	KeyType  shared.KeyType  // set for keys
+	KeyXType shared.KeyXType // set for key exchanges
+	Created  time.Time
+}
+
+// destroy - destroys the key material held by the entry
+func (e Entry) destroy() {
+	if nil != e.Key {
+		e.Key.Destroy()
+	}
+	if nil != e.KeyExchange {
+		e.KeyExchange.Destroy()
+	}
+}
+
+// regenerate - returns an entry with the same `kid` holding a newly generated key or key exchange of the same type
+func (e Entry) regenerate(opts ...shared.Option) (ne Entry, err error) {
+
+	ne.KID = e.KID
+
+	switch {
+	case nil != e.Key:
+		if !e.Key.IsPrivateKey() {
+			return Entry{}, fmt.Errorf("cannot rotate public key %q", e.KID)
+		}
+		if ne.Key, err = key.GenerateKey(e.Key.KeyType(), opts...); nil != err {
+			return Entry{}, err
+		}
+		// the new key is published under the same `kid` as the key it replaces
+		if err = ne.Key.SetKeyID(e.KID); nil != err {
+			ne.Key.Destroy()
+			return Entry{}, err
+		}
+
+	case nil != e.KeyExchange:
+		if !e.KeyExchange.IsPrivateKey() {
+			return Entry{}, fmt.Errorf("cannot rotate public key exchange %q", e.KID)
+		}
+		if ne.KeyExchange, err = key.GenerateKeyExchange(e.KeyExchange.KeyType(), opts...); nil != err {
+			return Entry{}, err
+		}
+	}
+
+	return
+}
+
+// KeyExchangeID - returns an identifier for a key exchange derived from its type and public key, since key exchanges have no `kid` of their own
+func KeyExchangeID(kx shared.KeyExchange) (kid string) {
+	h := sha256.New()
+	h.Write([]byte{byte(kx.KeyType())})
+	h.Write(kx.PublicKeyInstance())
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// encodeEntry - returns the details and serialized key material of an entry, the caller should clear `material` once it is stored
+func encodeEntry(e Entry, now time.Time) (info Info, material []byte, err error) {
+
+	info.KID, info.Created = e.KID, now.UTC()
+
+	switch {
+	case nil != e.Key && nil == e.KeyExchange:
+		info.Kind, info.KeyType = KindKey, e.Key.KeyType()
+		if material, err = e.Key.Bytes(); nil != err {
+			return Info{}, nil, err
+		}
+		if info.KID == "" {
+			// the key ID is set or a default one, either way it is in the serialized key
+			meta := struct {
+				KeyID string `json:"kid"`
+			}{}
+			if err = json.Unmarshal(material, &meta); nil != err {
+				clear(material)
+				return Info{}, nil, fmt.Errorf("error reading key id -> %w", err)
+			}
+			info.KID = meta.KeyID
+		}
+
+	case nil == e.Key && nil != e.KeyExchange:
+		info.Kind, info.KeyXType = KindKeyExchange, e.KeyExchange.KeyType()
+		if material, err = e.KeyExchange.Bytes(); nil != err {
+			return Info{}, nil, err
+		}
+		if info.KID == "" {
+			info.KID = KeyExchangeID(e.KeyExchange)
+		}
+
+	default:
+		return Info{}, nil, errors.New("entry must hold exactly one key or key exchange")
+	}
+
+	if info.KID == "" {
+		clear(material)
+		return Info{}, nil, errors.New("entry has no key id")
+	}
+
+	return
+}
+
+// decodeEntry - parses the key material of an entry, making sure it matches the stored details
+func decodeEntry(info Info, material []byte) (e Entry, err error) {
+
+	e.KID = info.KID
+
+	switch info.Kind {
+	case KindKey:
+		if e.Key, err = key.NewKeyFromBytes(material); nil != err {
+			return Entry{}, err
+		}
+		if e.Key.KeyType() != info.KeyType {
+			e.Key.Destroy()
+			return Entry{}, fmt.Errorf("stored key is %s, expected %s", e.Key.KeyType(), info.KeyType)
+		}
+
+	case KindKeyExchange:
+		if e.KeyExchange, err = key.NewKXFromBytes(material); nil != err {
+			return Entry{}, err
+		}
+		if e.KeyExchange.KeyType() != info.KeyXType {
+			e.KeyExchange.Destroy()
+			return Entry{}, fmt.Errorf("stored key exchange is %s, expected %s", e.KeyExchange.KeyType(), info.KeyXType)
+		}
+
+	default:
+		return Entry{}, fmt.Errorf("unknown entry kind %d", info.Kind)
+	}
+
+	return
+}
@@ -0,0 +1,337 @@
+// Package keystoretest checks `keystore.KeyStore` implementations behave the same as the backends in the keystore package
+package keystoretest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/svicknesh/key/v2"
+	"github.com/svicknesh/key/v2/keystore"
+	"github.com/svicknesh/key/v2/shared"
+)
+
+// Factory - returns a new empty keystore for each subtest, cleanup can be registered with `t.Cleanup`
+type Factory func(t *testing.T) (s keystore.KeyStore)
+
+// Run - runs the conformance tests against keystores returned by `newStore`
+func Run(t *testing.T, newStore Factory) {
+
+	t.Run("Keys", func(t *testing.T) { testKeys(t, newStore(t)) })
+	t.Run("KeyExchanges", func(t *testing.T) { testKeyExchanges(t, newStore(t)) })
+	t.Run("PublicKeys", func(t *testing.T) { testPublicKeys(t, newStore(t)) })
+	t.Run("InvalidEntries", func(t *testing.T) { testInvalidEntries(t, newStore(t)) })
+	t.Run("List", func(t *testing.T) { testList(t, newStore(t)) })
+	t.Run("Replace", func(t *testing.T) { testReplace(t, newStore(t)) })
+	t.Run("Delete", func(t *testing.T) { testDelete(t, newStore(t)) })
+	t.Run("Rotate", func(t *testing.T) { testRotate(t, newStore(t)) })
+	t.Run("Isolation", func(t *testing.T) { testIsolation(t, newStore(t)) })
+	t.Run("Concurrent", func(t *testing.T) { testConcurrent(t, newStore(t)) })
+}
+
+func generateKey(t *testing.T, kt shared.KeyType) (k shared.Key) {
+	t.Helper()
+	k, err := key.GenerateKey(kt)
+	if nil != err {
+		t.Fatalf("GenerateKey(%s): %v", kt, err)
+	}
+	return
+}
+
+func generateKX(t *testing.T, kxt shared.KeyXType) (kx shared.KeyExchange) {
+	t.Helper()
+	kx, err := key.GenerateKeyExchange(kxt)
+	if nil != err {
+		t.Fatalf("GenerateKeyExchange(%s): %v", kxt, err)
+	}
+	return
+}
+
+func put(t *testing.T, s keystore.KeyStore, e keystore.Entry) (kid string) {
+	t.Helper()
+	kid, err := s.Put(e)
+	if nil != err {
+		t.Fatalf("Put: %v", err)
+	}
+	return
+}
+
+func get(t *testing.T, s keystore.KeyStore, kid string) (e keystore.Entry) {
+	t.Helper()
+	e, err := s.Get(kid)
+	if nil != err {
+		t.Fatalf("Get(%q): %v", kid, err)
+	}
+	if e.KID != kid {
+		t.Errorf("Get(%q) returned entry %q", kid, e.KID)
+	}
+	return
+}
+
+func testKeys(t *testing.T, s keystore.KeyStore) {
+
+	for _, kt := range []shared.KeyType{key.ED25519, key.ECDSA256, key.ECDSA384} {
+		k := generateKey(t, kt)
+
+		// without a `kid` the key ID is used
+		kid := put(t, s, keystore.Entry{Key: k})
+		if kid == "" {
+			t.Fatalf("Put(%s) returned an empty key id", kt)
+		}
+
+		e := get(t, s, kid)
+		if nil == e.Key || nil != e.KeyExchange {
+			t.Fatalf("Get(%s) should return a key only", kt)
+		}
+		if e.Key.KeyType() != kt || !e.Key.Equal(k) {
+			t.Errorf("stored %s key does not match", kt)
+		}
+		if e.Key.GetKeyID() != kid {
+			t.Errorf("stored %s key has kid %q, want %q", kt, e.Key.GetKeyID(), kid)
+		}
+
+		signed, err := e.Key.SignMessage([]byte("hello, world"))
+		if nil != err || !k.VerifyMessage([]byte("hello, world"), signed) {
+			t.Errorf("stored %s key cannot sign: %v", kt, err)
+		}
+	}
+
+	// an explicit `kid` is kept
+	k := generateKey(t, key.ED25519)
+	if kid := put(t, s, keystore.Entry{KID: "signing", Key: k}); kid != "signing" {
+		t.Errorf("Put with kid returned %q, want signing", kid)
+	}
+	if e := get(t, s, "signing"); !e.Key.Equal(k) {
+		t.Error("stored key does not match")
+	}
+}
+
+func testKeyExchanges(t *testing.T, s keystore.KeyStore) {
+
+	for _, kxt := range []shared.KeyXType{key.CURVE25519, key.ECDH256, key.ECDH384} {
+		kx := generateKX(t, kxt)
+
+		kid := put(t, s, keystore.Entry{KeyExchange: kx})
+		if kid != keystore.KeyExchangeID(kx) {
+			t.Errorf("Put(%s) returned %q, want %q", kxt, kid, keystore.KeyExchangeID(kx))
+		}
+
+		e := get(t, s, kid)
+		if nil == e.KeyExchange || nil != e.Key {
+			t.Fatalf("Get(%s) should return a key exchange only", kxt)
+		}
+		if e.KeyExchange.KeyType() != kxt || !e.KeyExchange.Equal(kx) {
+			t.Errorf("stored %s key exchange does not match", kxt)
+		}
+	}
+}
+
+func testPublicKeys(t *testing.T, s keystore.KeyStore) {
+
+	kPub, err := generateKey(t, key.ECDSA256).PublicKey()
+	if nil != err {
+		t.Fatalf("PublicKey: %v", err)
+	}
+	kid := put(t, s, keystore.Entry{Key: kPub})
+	if e := get(t, s, kid); e.Key.IsPrivateKey() || !e.Key.PublicEqual(kPub) {
+		t.Error("stored public key does not match")
+	}
+
+	kxPub := generateKX(t, key.ECDH256).PublicKey()
+	kxid := put(t, s, keystore.Entry{KeyExchange: kxPub})
+	if e := get(t, s, kxid); e.KeyExchange.IsPrivateKey() || !e.KeyExchange.PublicEqual(kxPub) {
+		t.Error("stored public key exchange does not match")
+	}
+
+	// public keys cannot be rotated, there is no private key to replace
+	if _, err := s.Rotate(kid); nil == err {
+		t.Error("Rotate of a public key should fail")
+	}
+	if e := get(t, s, kid); !e.Key.PublicEqual(kPub) {
+		t.Error("failed Rotate should keep the entry")
+	}
+}
+
+func testInvalidEntries(t *testing.T, s keystore.KeyStore) {
+
+	k := generateKey(t, key.ED25519)
+	kx := generateKX(t, key.CURVE25519)
+
+	for name, e := range map[string]keystore.Entry{
+		"empty": {KID: "empty"},
+		"both":  {KID: "both", Key: k, KeyExchange: kx},
+	} {
+		if _, err := s.Put(e); nil == err {
+			t.Errorf("Put(%s) should fail", name)
+		}
+	}
+
+	if infos, err := s.List(); nil != err || len(infos) != 0 {
+		t.Errorf("List after invalid entries = %v, %v, want none", infos, err)
+	}
+}
+
+func testList(t *testing.T, s keystore.KeyStore) {
+
+	if infos, err := s.List(); nil != err || len(infos) != 0 {
+		t.Fatalf("List of an empty keystore = %v, %v", infos, err)
+	}
+
+	put(t, s, keystore.Entry{KID: "c", Key: generateKey(t, key.ECDSA384)})
+	put(t, s, keystore.Entry{KID: "a", KeyExchange: generateKX(t, key.ECDH256)})
+	put(t, s, keystore.Entry{KID: "b", Key: generateKey(t, key.ED25519)})
+
+	infos, err := s.List()
+	if nil != err {
+		t.Fatalf("List: %v", err)
+	}
+
+	want := []keystore.Info{
+		{KID: "a", Kind: keystore.KindKeyExchange, KeyXType: key.ECDH256},
+		{KID: "b", Kind: keystore.KindKey, KeyType: key.ED25519},
+		{KID: "c", Kind: keystore.KindKey, KeyType: key.ECDSA384},
+	}
+	if len(infos) != len(want) {
+		t.Fatalf("List returned %d entries, want %d", len(infos), len(want))
+	}
+	for i, info := range infos {
+		if info.Created.IsZero() {
+			t.Errorf("entry %q has no creation time", info.KID)
+		}
+		info.Created = want[i].Created
+		if info != want[i] {
+			t.Errorf("List()[%d] = %+v, want %+v", i, info, want[i])
+		}
+	}
+}
+
+func testReplace(t *testing.T, s keystore.KeyStore) {
+
+	put(t, s, keystore.Entry{KID: "signing", Key: generateKey(t, key.ED25519)})
+
+	// an entry may be replaced by a different type
+	kx := generateKX(t, key.CURVE25519)
+	put(t, s, keystore.Entry{KID: "signing", KeyExchange: kx})
+
+	e := get(t, s, "signing")
+	if nil != e.Key || nil == e.KeyExchange || !e.KeyExchange.Equal(kx) {
+		t.Error("Put should replace the entry")
+	}
+	if infos, _ := s.List(); len(infos) != 1 || infos[0].Kind != keystore.KindKeyExchange {
+		t.Errorf("List after replace = %+v", infos)
+	}
+}
+
+func testDelete(t *testing.T, s keystore.KeyStore) {
+
+	kid := put(t, s, keystore.Entry{Key: generateKey(t, key.ECDSA256)})
+
+	if err := s.Delete(kid); nil != err {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Get(kid); !errors.Is(err, keystore.ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+	if err := s.Delete(kid); !errors.Is(err, keystore.ErrNotFound) {
+		t.Errorf("Delete of a missing entry = %v, want ErrNotFound", err)
+	}
+	if _, err := s.Rotate(kid); !errors.Is(err, keystore.ErrNotFound) {
+		t.Errorf("Rotate of a missing entry = %v, want ErrNotFound", err)
+	}
+	if infos, _ := s.List(); len(infos) != 0 {
+		t.Errorf("List after Delete = %+v", infos)
+	}
+}
+
+func testRotate(t *testing.T, s keystore.KeyStore) {
+
+	k := generateKey(t, key.ECDSA256)
+	put(t, s, keystore.Entry{KID: "signing", Key: k})
+
+	e, err := s.Rotate("signing")
+	if nil != err {
+		t.Fatalf("Rotate key: %v", err)
+	}
+	if e.KID != "signing" || e.Key.KeyType() != key.ECDSA256 || e.Key.Equal(k) {
+		t.Error("Rotate should return a new key of the same type under the same kid")
+	}
+	if e.Key.GetKeyID() != "signing" {
+		t.Errorf("rotated key has kid %q, want signing", e.Key.GetKeyID())
+	}
+	if stored := get(t, s, "signing"); !stored.Key.Equal(e.Key) {
+		t.Error("Rotate should store the new key")
+	}
+
+	kx := generateKX(t, key.ECDH384)
+	put(t, s, keystore.Entry{KID: "exchange", KeyExchange: kx})
+
+	if e, err = s.Rotate("exchange"); nil != err {
+		t.Fatalf("Rotate key exchange: %v", err)
+	}
+	if e.KeyExchange.KeyType() != key.ECDH384 || e.KeyExchange.Equal(kx) {
+		t.Error("Rotate should return a new key exchange of the same type")
+	}
+	if stored := get(t, s, "exchange"); !stored.KeyExchange.Equal(e.KeyExchange) {
+		t.Error("Rotate should store the new key exchange")
+	}
+
+	// options are used when generating the new key
+	policy := &shared.Policy{Rules: []shared.Rule{{DenyKeyTypes: []shared.KeyType{key.ECDSA256}}}}
+	if _, err = s.Rotate("signing", shared.WithPolicy(policy)); !errors.Is(err, shared.ErrPolicyViolation) {
+		t.Errorf("Rotate with policy = %v, want ErrPolicyViolation", err)
+	}
+}
+
+func testIsolation(t *testing.T, s keystore.KeyStore) {
+
+	k := generateKey(t, key.ED25519)
+	kid := put(t, s, keystore.Entry{Key: k})
+	pub, err := k.PublicKey()
+	if nil != err {
+		t.Fatalf("PublicKey: %v", err)
+	}
+
+	// destroying the key given to or returned by the store does not affect the stored entry
+	k.Destroy()
+	get(t, s, kid).Key.Destroy()
+
+	if e := get(t, s, kid); !e.Key.IsPrivateKey() || !e.Key.PublicEqual(pub) {
+		t.Error("stored key should not share key material with the caller")
+	}
+}
+
+func testConcurrent(t *testing.T, s keystore.KeyStore) {
+
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			kid := fmt.Sprintf("key-%d", i)
+			k, err := key.GenerateKey(key.ED25519)
+			if nil != err {
+				t.Errorf("GenerateKey: %v", err)
+				return
+			}
+			if _, err = s.Put(keystore.Entry{KID: kid, Key: k}); nil != err {
+				t.Errorf("Put(%q): %v", kid, err)
+				return
+			}
+			for range 3 {
+				if _, err := s.Rotate(kid); nil != err {
+					t.Errorf("Rotate(%q): %v", kid, err)
+				}
+				if _, err := s.Get(kid); nil != err {
+					t.Errorf("Get(%q): %v", kid, err)
+				}
+				if _, err := s.List(); nil != err {
+					t.Errorf("List: %v", err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	if infos, err := s.List(); nil != err || len(infos) != 8 {
+		t.Errorf("List after concurrent use returned %d entries, %v, want 8", len(infos), err)
+	}
+}
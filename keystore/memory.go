@@ -0,0 +1,135 @@
+package keystore
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/svicknesh/key/v2/shared"
+)
+
+// memoryEntry - serialized entry, so keys given to or returned by the store do not share key material with it
+type memoryEntry struct {
+	info     Info
+	material []byte
+}
+
+// MemoryStore - keystore keeping entries in memory, for tests and short lived processes
+type MemoryStore struct {
+	mu      sync.RWMutex
+	entries map[string]*memoryEntry
+}
+
+// NewMemoryStore - returns an empty in-memory keystore
+func NewMemoryStore() (s *MemoryStore) {
+	return &MemoryStore{entries: make(map[string]*memoryEntry)}
+}
+
+// Get - returns the entry with the given `kid`
+func (s *MemoryStore) Get(kid string) (e Entry, err error) {
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if e, err = s.get(kid); nil != err {
+		return Entry{}, fmt.Errorf("keystore-get: %w", err)
+	}
+
+	return
+}
+
+// Put - stores the entry, replacing any entry with the same `kid`, and returns the `kid` it was stored under
+func (s *MemoryStore) Put(e Entry) (kid string, err error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if kid, err = s.put(e); nil != err {
+		return "", fmt.Errorf("keystore-put: %w", err)
+	}
+
+	return
+}
+
+// List - returns the details of every entry sorted by `kid`
+func (s *MemoryStore) List() (infos []Info, err error) {
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, kid := range slices.Sorted(maps.Keys(s.entries)) {
+		infos = append(infos, s.entries[kid].info)
+	}
+
+	return
+}
+
+// Delete - removes the entry with the given `kid`, clearing its key material
+func (s *MemoryStore) Delete(kid string) (err error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	me, ok := s.entries[kid]
+	if !ok {
+		return fmt.Errorf("keystore-delete: %w %q", ErrNotFound, kid)
+	}
+
+	clear(me.material)
+	delete(s.entries, kid)
+
+	return
+}
+
+// Rotate - replaces the entry with a newly generated key or key exchange of the same type under the same `kid`, the old key material is destroyed
+func (s *MemoryStore) Rotate(kid string, opts ...shared.Option) (e Entry, err error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, err := s.get(kid)
+	if nil != err {
+		return Entry{}, fmt.Errorf("keystore-rotate: %w", err)
+	}
+	defer old.destroy()
+
+	if e, err = old.regenerate(opts...); nil != err {
+		return Entry{}, fmt.Errorf("keystore-rotate: %w", err)
+	}
+
+	if _, err = s.put(e); nil != err {
+		e.destroy()
+		return Entry{}, fmt.Errorf("keystore-rotate: %w", err)
+	}
+
+	return
+}
+
+// get - parses an entry, the caller must hold the lock
+func (s *MemoryStore) get(kid string) (e Entry, err error) {
+
+	me, ok := s.entries[kid]
+	if !ok {
+		return Entry{}, fmt.Errorf("%w %q", ErrNotFound, kid)
+	}
+
+	return decodeEntry(me.info, me.material)
+}
+
+// put - serializes an entry, the caller must hold the write lock
+func (s *MemoryStore) put(e Entry) (kid string, err error) {
+
+	info, material, err := encodeEntry(e, time.Now())
+	if nil != err {
+		return
+	}
+
+	if old, ok := s.entries[info.KID]; ok {
+		clear(old.material)
+	}
+	s.entries[info.KID] = &memoryEntry{info: info, material: material}
+
+	return info.KID, nil
+}
@@ -0,0 +1,212 @@
+package keywrap
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/svicknesh/enum2str"
+	"github.com/svicknesh/key/v2"
+	"github.com/svicknesh/key/v2/kx/crv"
+	"github.com/svicknesh/key/v2/kx/ecdhc"
+	"github.com/svicknesh/key/v2/shared"
+)
+
+// Format - encoding of the private key before it is wrapped
+type Format uint8
+
+const (
+	// PKCS8 - PKCS #8 DER, understood by HSMs and `x509.ParsePKCS8PrivateKey`, the `kid` and validity of a key are not kept
+	PKCS8 Format = iota + 1
+
+	// JWK - JSON Web Key as returned by `Bytes`, for keys only
+	JWK
+
+	// Raw - encoding returned by `Bytes` of a key exchange, for key exchanges only
+	Raw
+)
+
+// String - returns string name for a given format
+func (f Format) String() (str string) {
+	return enum2str.String(f, "unknown", "pkcs8", "jwk", "raw")
+}
+
+// WrapKey - encodes the private key in the given format and wraps it with the AES-256 key-encryption key using RFC 5649
+func WrapKey(kek []byte, k shared.Key, format Format) (wrapped []byte, err error) {
+
+	if nil == k || !k.IsPrivateKey() {
+		return nil, errors.New("keywrap-wrapkey: a private key is required")
+	}
+
+	var material []byte
+	switch format {
+	case PKCS8:
+		material, err = x509.MarshalPKCS8PrivateKey(k.PrivateKeyInstance())
+	case JWK:
+		material, err = k.Bytes()
+	default:
+		err = fmt.Errorf("unsupported format %s for keys", format)
+	}
+	if nil != err {
+		return nil, fmt.Errorf("keywrap-wrapkey: %w", err)
+	}
+	defer clear(material)
+
+	if wrapped, err = WrapPad(kek, material); nil != err {
+		return nil, fmt.Errorf("keywrap-wrapkey: %w", err)
+	}
+
+	return
+}
+
+// UnwrapKey - unwraps a key wrapped by `WrapKey` in the given format, options such as `key.WithStrict` are used to parse it
+func UnwrapKey(kek, wrapped []byte, format Format, opts ...shared.Option) (k shared.Key, err error) {
+
+	material, err := UnwrapPad(kek, wrapped)
+	if nil != err {
+		return nil, fmt.Errorf("keywrap-unwrapkey: %w", err)
+	}
+	defer clear(material)
+
+	switch format {
+	case PKCS8:
+		var rkey any
+		if rkey, err = x509.ParsePKCS8PrivateKey(material); nil != err {
+			break
+		}
+		if _, ok := rkey.(*ecdh.PrivateKey); ok {
+			err = errors.New("wrapped key is a key exchange, use `UnwrapKeyExchange`")
+			break
+		}
+		k, err = key.NewFromRawKey(rkey, opts...)
+	case JWK:
+		k, err = key.NewKeyFromBytes(material, opts...)
+	default:
+		err = fmt.Errorf("unsupported format %s for keys", format)
+	}
+	if nil != err {
+		return nil, fmt.Errorf("keywrap-unwrapkey: %w", err)
+	}
+
+	return
+}
+
+// WrapKeyExchange - encodes the private key exchange in the given format and wraps it with the AES-256 key-encryption key using RFC 5649
+func WrapKeyExchange(kek []byte, kx shared.KeyExchange, format Format) (wrapped []byte, err error) {
+
+	if nil == kx || !kx.IsPrivateKey() {
+		return nil, errors.New("keywrap-wrapkeyexchange: a private key exchange is required")
+	}
+
+	material, err := kx.Bytes()
+	if nil != err {
+		return nil, fmt.Errorf("keywrap-wrapkeyexchange: %w", err)
+	}
+	defer clear(material)
+
+	switch format {
+	case PKCS8:
+		var priv *ecdh.PrivateKey
+		if priv, err = ecdhPrivateKey(kx.KeyType(), material[1:]); nil == err {
+			material, err = x509.MarshalPKCS8PrivateKey(priv)
+			defer clear(material)
+		}
+	case Raw:
+	default:
+		err = fmt.Errorf("unsupported format %s for key exchanges", format)
+	}
+	if nil != err {
+		return nil, fmt.Errorf("keywrap-wrapkeyexchange: %w", err)
+	}
+
+	if wrapped, err = WrapPad(kek, material); nil != err {
+		return nil, fmt.Errorf("keywrap-wrapkeyexchange: %w", err)
+	}
+
+	return
+}
+
+// UnwrapKeyExchange - unwraps a key exchange wrapped by `WrapKeyExchange` in the given format, options such as `key.WithStrict` are used to parse it
+func UnwrapKeyExchange(kek, wrapped []byte, format Format, opts ...shared.Option) (kx shared.KeyExchange, err error) {
+
+	material, err := UnwrapPad(kek, wrapped)
+	if nil != err {
+		return nil, fmt.Errorf("keywrap-unwrapkeyexchange: %w", err)
+	}
+	defer clear(material)
+
+	switch format {
+	case PKCS8:
+		var kxBytes []byte
+		if kxBytes, err = kxBytesFromPKCS8(material); nil == err {
+			kx, err = key.NewKXFromBytes(kxBytes, opts...)
+			clear(kxBytes)
+		}
+	case Raw:
+		kx, err = key.NewKXFromBytes(material, opts...)
+	default:
+		err = fmt.Errorf("unsupported format %s for key exchanges", format)
+	}
+	if nil != err {
+		return nil, fmt.Errorf("keywrap-unwrapkeyexchange: %w", err)
+	}
+
+	return
+}
+
+// ecdhPrivateKey - returns the standard library private key for the private key exchange bytes
+func ecdhPrivateKey(kxt shared.KeyXType, priv []byte) (k *ecdh.PrivateKey, err error) {
+
+	switch kxt {
+	case shared.CURVE25519:
+		return ecdh.X25519().NewPrivateKey(priv)
+	case shared.ECDH256:
+		return ecdh.P256().NewPrivateKey(priv)
+	case shared.ECDH384:
+		return ecdh.P384().NewPrivateKey(priv)
+	case shared.ECDH521:
+		return ecdh.P521().NewPrivateKey(priv)
+	}
+
+	return nil, fmt.Errorf("unsupported key exchange type %s", kxt)
+}
+
+// kxBytesFromPKCS8 - converts a PKCS #8 X25519 or NIST curve private key into the encoding returned by `Bytes` of a key exchange
+func kxBytesFromPKCS8(der []byte) (kxBytes []byte, err error) {
+
+	rkey, err := x509.ParsePKCS8PrivateKey(der)
+	if nil != err {
+		return
+	}
+
+	var priv *ecdh.PrivateKey
+	switch rk := rkey.(type) {
+	case *ecdh.PrivateKey:
+		priv = rk
+	case *ecdsa.PrivateKey:
+		// NIST curve keys are parsed as ECDSA keys, they are the same keys used for a different purpose
+		if priv, err = rk.ECDH(); nil != err {
+			return
+		}
+	default:
+		return nil, fmt.Errorf("wrapped key of type %T is not a key exchange", rk)
+	}
+
+	var identifier uint8
+	switch priv.Curve() {
+	case ecdh.X25519():
+		identifier = crv.TypeCrvPriv
+	case ecdh.P256():
+		identifier = ecdhc.TypeECDHPriv256
+	case ecdh.P384():
+		identifier = ecdhc.TypeECDHPriv384
+	case ecdh.P521():
+		identifier = ecdhc.TypeECDHPriv521
+	default:
+		return nil, fmt.Errorf("unsupported curve %s", priv.Curve())
+	}
+
+	return append([]byte{identifier}, priv.Bytes()...), nil
+}
@@ -0,0 +1,195 @@
+package keywrap
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// KEKSize - size in bytes of the key-encryption key, keys are wrapped with AES-256 only
+const KEKSize = 32
+
+// ErrUnwrap - returned when the wrapped key fails its integrity check, because the KEK is wrong or the data was altered
+var ErrUnwrap = errors.New("key unwrap integrity check failed")
+
+var (
+	defaultIV = [8]byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6} // RFC 3394 section 2.2.3.1
+	padIV     = [4]byte{0xa6, 0x59, 0x59, 0xa6}                         // RFC 5649 section 3
+)
+
+// Wrap - wraps the plaintext key with the AES-256 key-encryption key as specified by RFC 3394, the plaintext must be a multiple of 8 bytes and at least 16 bytes
+func Wrap(kek, plaintext []byte) (wrapped []byte, err error) {
+
+	if len(plaintext) < 16 || len(plaintext)%8 != 0 {
+		return nil, fmt.Errorf("keywrap-wrap: plaintext must be a multiple of 8 bytes and at least 16 bytes, got %d", len(plaintext))
+	}
+
+	block, err := newCipher(kek)
+	if nil != err {
+		return nil, fmt.Errorf("keywrap-wrap: %w", err)
+	}
+
+	return wrap(block, defaultIV, plaintext), nil
+}
+
+// Unwrap - unwraps a key wrapped by `Wrap`, returning `ErrUnwrap` if the integrity check fails
+func Unwrap(kek, wrapped []byte) (plaintext []byte, err error) {
+
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, fmt.Errorf("keywrap-unwrap: wrapped key must be a multiple of 8 bytes and at least 24 bytes, got %d", len(wrapped))
+	}
+
+	block, err := newCipher(kek)
+	if nil != err {
+		return nil, fmt.Errorf("keywrap-unwrap: %w", err)
+	}
+
+	iv, plaintext := unwrap(block, wrapped)
+	if subtle.ConstantTimeCompare(iv[:], defaultIV[:]) != 1 {
+		clear(plaintext)
+		return nil, fmt.Errorf("keywrap-unwrap: %w", ErrUnwrap)
+	}
+
+	return
+}
+
+// WrapPad - wraps a plaintext key of any length with the AES-256 key-encryption key as specified by RFC 5649
+func WrapPad(kek, plaintext []byte) (wrapped []byte, err error) {
+
+	if len(plaintext) == 0 || uint64(len(plaintext)) > 0xffffffff {
+		return nil, fmt.Errorf("keywrap-wrappad: plaintext must be between 1 byte and 4 GiB, got %d", len(plaintext))
+	}
+
+	block, err := newCipher(kek)
+	if nil != err {
+		return nil, fmt.Errorf("keywrap-wrappad: %w", err)
+	}
+
+	var iv [8]byte
+	copy(iv[:], padIV[:])
+	binary.BigEndian.PutUint32(iv[4:], uint32(len(plaintext)))
+
+	padded := make([]byte, (len(plaintext)+7)/8*8)
+	copy(padded, plaintext)
+	defer clear(padded)
+
+	if len(padded) == 8 {
+		// a single block is encrypted directly, RFC 5649 section 4.1
+		wrapped = make([]byte, 16)
+		copy(wrapped, iv[:])
+		copy(wrapped[8:], padded)
+		block.Encrypt(wrapped, wrapped)
+		return
+	}
+
+	return wrap(block, iv, padded), nil
+}
+
+// UnwrapPad - unwraps a key wrapped by `WrapPad`, returning `ErrUnwrap` if the integrity check fails
+func UnwrapPad(kek, wrapped []byte) (plaintext []byte, err error) {
+
+	if len(wrapped) < 16 || len(wrapped)%8 != 0 {
+		return nil, fmt.Errorf("keywrap-unwrappad: wrapped key must be a multiple of 8 bytes and at least 16 bytes, got %d", len(wrapped))
+	}
+
+	block, err := newCipher(kek)
+	if nil != err {
+		return nil, fmt.Errorf("keywrap-unwrappad: %w", err)
+	}
+
+	var iv [8]byte
+	var padded []byte
+	if len(wrapped) == 16 {
+		b := make([]byte, 16)
+		block.Decrypt(b, wrapped)
+		copy(iv[:], b)
+		padded = b[8:]
+	} else {
+		iv, padded = unwrap(block, wrapped)
+	}
+
+	// RFC 5649 section 3, the length must fall within the last block and the padding must be zero
+	mli := int(binary.BigEndian.Uint32(iv[4:]))
+	if subtle.ConstantTimeCompare(iv[:4], padIV[:]) != 1 || mli <= len(padded)-8 || mli > len(padded) {
+		clear(padded)
+		return nil, fmt.Errorf("keywrap-unwrappad: %w", ErrUnwrap)
+	}
+
+	var pad byte
+	for _, b := range padded[mli:] {
+		pad |= b
+	}
+	if pad != 0 {
+		clear(padded)
+		return nil, fmt.Errorf("keywrap-unwrappad: %w", ErrUnwrap)
+	}
+
+	return padded[:mli], nil
+}
+
+// newCipher - returns AES-256 keyed with the key-encryption key, shorter AES keys are refused
+func newCipher(kek []byte) (block cipher.Block, err error) {
+
+	if len(kek) != KEKSize {
+		return nil, fmt.Errorf("key-encryption key must be %d bytes for AES-256, got %d", KEKSize, len(kek))
+	}
+
+	return aes.NewCipher(kek)
+}
+
+// wrap - wrapping process of RFC 3394 section 2.2.1 with the given initial value
+func wrap(block cipher.Block, iv [8]byte, plaintext []byte) (wrapped []byte) {
+
+	n := len(plaintext) / 8
+	wrapped = make([]byte, 8+len(plaintext))
+	copy(wrapped[8:], plaintext)
+
+	a := iv
+	var b [16]byte
+	for j := range 6 {
+		for i := 1; i <= n; i++ {
+			r := wrapped[i*8 : i*8+8]
+			copy(b[:8], a[:])
+			copy(b[8:], r)
+			block.Encrypt(b[:], b[:])
+
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(a[:], binary.BigEndian.Uint64(b[:8])^t)
+			copy(r, b[8:])
+		}
+	}
+	clear(b[:])
+
+	copy(wrapped, a[:])
+
+	return
+}
+
+// unwrap - unwrapping process of RFC 3394 section 2.2.2, returning the initial value for the caller to check
+func unwrap(block cipher.Block, wrapped []byte) (iv [8]byte, plaintext []byte) {
+
+	n := len(wrapped)/8 - 1
+	plaintext = make([]byte, n*8)
+	copy(plaintext, wrapped[8:])
+
+	copy(iv[:], wrapped[:8])
+	var b [16]byte
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			r := plaintext[(i-1)*8 : i*8]
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b[:8], binary.BigEndian.Uint64(iv[:])^t)
+			copy(b[8:], r)
+			block.Decrypt(b[:], b[:])
+
+			copy(iv[:], b[:8])
+			copy(r, b[8:])
+		}
+	}
+	clear(b[:])
+
+	return
+}
@@ -1,6 +1,7 @@
 package crv
 
 import (
+	"crypto/subtle"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -20,6 +21,7 @@ const (
 type KX struct {
 	priv, pub     [32]byte
 	isPriv, isPub bool
+	destroyed     bool
 }
 
 // Bytes - returns bytes of the key
@@ -33,6 +35,8 @@ func (kx *KX) Bytes() (bytes []byte, err error) {
 	} else if kx.isPub {
 		bytes[0] = TypeCrvPub
 		bytes = append(bytes, kx.pub[:]...)
+	} else if kx.destroyed {
+		return nil, fmt.Errorf("curve25519-bytes: %w", shared.ErrKeyDestroyed)
 	} else {
 		return nil, fmt.Errorf("curve25519-bytes: neither public nor private key found")
 	}
@@ -89,18 +93,45 @@ func (kx *KX) KeyType() (kxt shared.KeyXType) {
 	return shared.CURVE25519
 }
 
-// PublicKey - returns instance of public key of type Key Exchange
+// SharedSecret - returns the shared secret of this private key and the peer public key, peer keys of small order are rejected so the secret always depends on both keys
 func (kx *KX) SharedSecret(kxPub2 shared.KeyExchange) (sharedsecret []byte, err error) {
+	if kx.destroyed {
+		return nil, fmt.Errorf("curve25519-sharedsecret: %w", shared.ErrKeyDestroyed)
+	}
+
+	if err = shared.CheckFIPSKeyX(shared.CURVE25519); nil != err {
+		return nil, fmt.Errorf("curve25519-sharedsecret: %w", err)
+	}
+
 	if !kx.isPriv {
-		return nil, errors.New("curve25519-publickey: no private key exists to generate shared secret")
+		return nil, errors.New("curve25519-sharedsecret: no private key exists to generate shared secret")
+	}
+
+	if nil == kxPub2 || !kxPub2.IsPublicKey() {
+		return nil, errors.New("curve25519-sharedsecret: no public key exists in paramameter to generate shared secret")
+	}
+
+	if kxPub2.KeyType() != shared.CURVE25519 {
+		return nil, fmt.Errorf("curve25519-sharedsecret: curve mismatch: local=%s, peer=%s", shared.CURVE25519, kxPub2.KeyType())
+	}
+
+	pub := kxPub2.PublicKeyInstance()
+	if len(pub) != curve25519.PointSize {
+		return nil, fmt.Errorf("curve25519-sharedsecret: %w: invalid peer public key length %d", shared.ErrInvalidKey, len(pub))
 	}
 
-	if !kxPub2.IsPublicKey() {
-		return nil, errors.New("curve25519-publickey: no public key exists in paramameter to generate shared secret")
+	// X25519 returns an error when the result is all zeroes, which happens exactly when the peer key is of small order
+	sharedsecret, err = curve25519.X25519(kx.priv[:], pub)
+	if nil != err || isZero(sharedsecret) {
+		return nil, fmt.Errorf("curve25519-sharedsecret: %w: peer public key is of small order", shared.ErrInvalidKey)
 	}
 
-	return curve25519.X25519(kx.priv[:], kxPub2.PublicKeyInstance())
+	return
+}
 
+// isZero - returns if all bytes are zero in constant time
+func isZero(b []byte) (zero bool) {
+	return subtle.ConstantTimeCompare(b, make([]byte, len(b))) == 1
 }
 
 // Length - returns length of the private or public key
@@ -108,3 +139,62 @@ func (kx *KX) Length() (length int) {
 	bytes, _ := kx.Bytes()
 	return len(bytes)
 }
+
+// Destroy - zeroes the private and public key, every later use of the key exchange fails
+func (kx *KX) Destroy() {
+	clear(kx.priv[:])
+	clear(kx.pub[:])
+	kx.isPriv, kx.isPub = false, false
+	kx.destroyed = true
+}
+
+// Equal - returns if `other` holds the same key material in constant time, both must be private or both public key exchanges
+func (kx *KX) Equal(other shared.KeyExchange) (ok bool) {
+	if nil == other || other.KeyType() != kx.KeyType() {
+		return false
+	}
+
+	// the encoded bytes hold the raw key behind an identifier for the key type and whether it is private
+	b1, err1 := kx.Bytes()
+	b2, err2 := other.Bytes()
+
+	return nil == err1 && nil == err2 && subtle.ConstantTimeCompare(b1, b2) == 1
+}
+
+// PublicEqual - returns if the public key of `other` matches the public key of this key exchange in constant time, private key exchanges compare using their public half
+func (kx *KX) PublicEqual(other shared.KeyExchange) (ok bool) {
+	if nil == other || other.KeyType() != kx.KeyType() {
+		return false
+	}
+
+	pub1, pub2 := kx.PublicKeyInstance(), other.PublicKeyInstance()
+
+	return len(pub1) != 0 && subtle.ConstantTimeCompare(pub1, pub2) == 1
+}
+
+// Validate - checks the private key is not all zeroes and the public key is not a point of small order
+func (kx *KX) Validate() (err error) {
+
+	if kx.destroyed {
+		return fmt.Errorf("curve25519-validate: %w", shared.ErrKeyDestroyed)
+	}
+
+	if kx.isPriv {
+		if kx.priv == [32]byte{} {
+			return fmt.Errorf("curve25519-validate: %w: private key is all zeroes", shared.ErrInvalidKey)
+		}
+
+		return
+	}
+
+	if !kx.isPub {
+		return fmt.Errorf("curve25519-validate: %w: neither public nor private key found", shared.ErrInvalidKey)
+	}
+
+	// clamped scalars are a multiple of the cofactor, so X25519 only fails for points of small order
+	if _, err = curve25519.X25519(curve25519.Basepoint, kx.pub[:]); nil != err {
+		return fmt.Errorf("curve25519-validate: %w: public key is of small order", shared.ErrInvalidKey)
+	}
+
+	return
+}
@@ -1,22 +1,29 @@
 package crv
 
 import (
-	"crypto/rand"
 	"fmt"
+	"io"
+
+	"github.com/svicknesh/key/v2/shared"
 )
 
 // Generate - generates a new Curve25519 public/private key
-func Generate() (kx *KX, err error) {
+func Generate(opts ...shared.Option) (kx *KX, err error) {
+	if err = shared.CheckFIPSKeyX(shared.CURVE25519); nil != err {
+		return nil, fmt.Errorf("curve25519-generate: %w", err)
+	}
+
 	kx = new(KX)
+	o := shared.NewOptions(opts...)
 
 	priv := make([]byte, 32)
-	_, err = rand.Read(priv)
+	_, err = io.ReadFull(o.Rand, priv)
 	if nil != err {
 		return nil, fmt.Errorf("curve25519-generate: error generating CURVE25519 -> %w", err)
 	}
 
 	copy(kx.priv[:], priv)
-	priv = nil
+	clear(priv)
 	kx.isPriv = true
 
 	return
@@ -25,6 +32,10 @@ func Generate() (kx *KX, err error) {
 // New - returns new instnace of key exchange from given bytes
 func New(kxBytes []byte) (kx *KX, err error) {
 
+	if err = shared.CheckFIPSKeyX(shared.CURVE25519); nil != err {
+		return nil, fmt.Errorf("curve25519-new: %w", err)
+	}
+
 	if len(kxBytes) < 2 {
 		return nil, fmt.Errorf("curve25519-new: input too short, need at least 2 bytes")
 	}
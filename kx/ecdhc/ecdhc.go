@@ -2,6 +2,7 @@ package ecdhc
 
 import (
 	"crypto/ecdh"
+	"crypto/subtle"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -34,6 +35,7 @@ type KX struct {
 	priv          *ecdh.PrivateKey
 	pub           *ecdh.PublicKey
 	isPriv, isPub bool
+	destroyed     bool
 }
 
 // Bytes - returns JSON encoded bytes of the key
@@ -66,6 +68,8 @@ func (kx *KX) Bytes() (bytes []byte, err error) {
 		}
 
 		bytes = append(bytes, kx.pub.Bytes()...)
+	} else if kx.destroyed {
+		return nil, fmt.Errorf("ecdh-bytes: %w", shared.ErrKeyDestroyed)
 	} else {
 		return nil, fmt.Errorf("curve25519-bytes: neither public nor private key found")
 	}
@@ -122,13 +126,17 @@ func (kx *KX) KeyType() (kxt shared.KeyXType) {
 	return kx.kxt
 }
 
-// PublicKey - returns instance of public key of type Key Exchange
+// SharedSecret - returns the shared secret of this private key and the peer public key, peer keys must be valid points on the same curve so the secret always depends on both keys
 func (kx *KX) SharedSecret(kxPub shared.KeyExchange) (sharedsecret []byte, err error) {
+	if kx.destroyed {
+		return nil, fmt.Errorf("ecdh-sharedsecret: %w", shared.ErrKeyDestroyed)
+	}
+
 	if !kx.isPriv {
 		return nil, errors.New("ecdh-sharedsecret: no private key exists for shared secret generation")
 	}
 
-	if !kxPub.IsPublicKey() {
+	if nil == kxPub || !kxPub.IsPublicKey() {
 		return nil, errors.New("ecdh-sharedsecret: no public key exists in paramameter for shared secret generation")
 	}
 
@@ -136,24 +144,23 @@ func (kx *KX) SharedSecret(kxPub shared.KeyExchange) (sharedsecret []byte, err e
 		return nil, fmt.Errorf("ecdh-sharedsecret: curve mismatch: local=%s, peer=%s", kx.kxt, kxPub.KeyType())
 	}
 
-	pub := new(ecdh.PublicKey)
-	switch kxPub.KeyType() {
-	case shared.ECDH256:
-		pub, err = ecdh.P256().NewPublicKey(kxPub.PublicKeyInstance())
-	case shared.ECDH384:
-		pub, err = ecdh.P384().NewPublicKey(kxPub.PublicKeyInstance())
-	case shared.ECDH521:
-		pub, err = ecdh.P521().NewPublicKey(kxPub.PublicKeyInstance())
-	default:
-		err = errors.New("unsupported ECDH key type for shared secret generation")
+	// parsing rejects the point at infinity and points that are not on the curve, the NIST curves have prime order so no small subgroup exists
+	curve, err := curveFor(kxPub.KeyType())
+	if nil != err {
+		return nil, fmt.Errorf("ecdh-sharedsecret: %w", err)
 	}
 
+	pub, err := curve.NewPublicKey(kxPub.PublicKeyInstance())
 	if nil != err {
-		return nil, fmt.Errorf("ecdh-sharedsecret: %w", err)
+		return nil, fmt.Errorf("ecdh-sharedsecret: %w: %w", shared.ErrInvalidKey, err)
 	}
 
-	return kx.priv.ECDH(pub)
+	sharedsecret, err = kx.priv.ECDH(pub)
+	if nil != err || subtle.ConstantTimeCompare(sharedsecret, make([]byte, len(sharedsecret))) == 1 {
+		return nil, fmt.Errorf("ecdh-sharedsecret: %w: shared secret is not contributory", shared.ErrInvalidKey)
+	}
 
+	return
 }
 
 // Length - returns length of the private or public key
@@ -161,3 +168,58 @@ func (kx *KX) Length() (length int) {
 	bytes, _ := kx.Bytes()
 	return len(bytes)
 }
+
+// Destroy - drops all key material, every later use of the key exchange fails.
+// `ecdh.PrivateKey` offers no way to zero its scalar, it is released for garbage collection instead.
+func (kx *KX) Destroy() {
+	kx.priv, kx.pub = nil, nil
+	kx.isPriv, kx.isPub = false, false
+	kx.destroyed = true
+}
+
+// Equal - returns if `other` holds the same key material in constant time, both must be private or both public key exchanges
+func (kx *KX) Equal(other shared.KeyExchange) (ok bool) {
+	if nil == other || other.KeyType() != kx.KeyType() {
+		return false
+	}
+
+	// the encoded bytes hold the raw key behind an identifier for the key type and whether it is private
+	b1, err1 := kx.Bytes()
+	b2, err2 := other.Bytes()
+
+	return nil == err1 && nil == err2 && subtle.ConstantTimeCompare(b1, b2) == 1
+}
+
+// PublicEqual - returns if the public key of `other` matches the public key of this key exchange in constant time, private key exchanges compare using their public half
+func (kx *KX) PublicEqual(other shared.KeyExchange) (ok bool) {
+	if nil == other || other.KeyType() != kx.KeyType() {
+		return false
+	}
+
+	pub1, pub2 := kx.PublicKeyInstance(), other.PublicKeyInstance()
+
+	return len(pub1) != 0 && subtle.ConstantTimeCompare(pub1, pub2) == 1
+}
+
+// Validate - checks the public key is a point on the curve of the key exchange type
+func (kx *KX) Validate() (err error) {
+
+	if kx.destroyed {
+		return fmt.Errorf("ecdh-validate: %w", shared.ErrKeyDestroyed)
+	}
+
+	pub := kx.PublicKeyInstance()
+	if len(pub) == 0 {
+		return fmt.Errorf("ecdh-validate: %w: neither public nor private key found", shared.ErrInvalidKey)
+	}
+
+	curve, err := curveFor(kx.kxt)
+	if nil == err {
+		_, err = curve.NewPublicKey(pub)
+	}
+	if nil != err {
+		return fmt.Errorf("ecdh-validate: %w: %w", shared.ErrInvalidKey, err)
+	}
+
+	return
+}
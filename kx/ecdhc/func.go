@@ -8,21 +8,36 @@ import (
 	"github.com/svicknesh/key/v2/shared"
 )
 
-// Generate - generates a new EC Diffie Hellman public/private key from existing ECDSA keys
-func Generate(kxt shared.KeyXType) (kx *KX, err error) {
+// Generate - generates a new EC Diffie Hellman public/private key
+func Generate(kxt shared.KeyXType, opts ...shared.Option) (kx *KX, err error) {
 	kx = new(KX)
+	o := shared.NewOptions(opts...)
+
+	var curve ecdh.Curve
+	var size int
+	var mask byte = 0xff
 
 	switch kxt {
 	case shared.ECDH256:
-		kx.priv, err = ecdh.P256().GenerateKey(rand.Reader)
+		curve, size = ecdh.P256(), 32
 	case shared.ECDH384:
-		kx.priv, err = ecdh.P384().GenerateKey(rand.Reader)
+		curve, size = ecdh.P384(), 48
 	case shared.ECDH521:
-		kx.priv, err = ecdh.P521().GenerateKey(rand.Reader)
+		curve, size, mask = ecdh.P521(), 66, 0x01 // P-521 scalars only use the lowest bit of the first byte
 	default:
 		return nil, fmt.Errorf("ecdh-generate: unsupported key type for ECDH generation")
 	}
 
+	if o.IsDefaultRand() {
+		kx.priv, err = curve.GenerateKey(rand.Reader)
+	} else {
+		// the standard library ignores custom readers, so the scalar is read from the reader directly
+		err = shared.SampleScalar(o.Rand, size, mask, func(b []byte) (err error) {
+			kx.priv, err = curve.NewPrivateKey(b)
+			return
+		})
+	}
+
 	if nil != err {
 		return nil, fmt.Errorf("ecdh-generate: error generating ECDH key -> %w", err)
 	}
@@ -100,3 +115,18 @@ func New(kxBytes []byte) (kx *KX, err error) {
 
 	return
 }
+
+// curveFor - returns the curve used by the key exchange type
+func curveFor(kxt shared.KeyXType) (curve ecdh.Curve, err error) {
+
+	switch kxt {
+	case shared.ECDH256:
+		return ecdh.P256(), nil
+	case shared.ECDH384:
+		return ecdh.P384(), nil
+	case shared.ECDH521:
+		return ecdh.P521(), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key exchange type %s for ECDH", kxt)
+}
@@ -0,0 +1,140 @@
+package key
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/svicknesh/key/v2/asym/ed"
+	"github.com/svicknesh/key/v2/kx/crv"
+	"github.com/svicknesh/key/v2/mnemonic"
+	"github.com/svicknesh/key/v2/shared"
+)
+
+// seedMnemonicWords - number of words of a BIP-39 mnemonic holding a master seed, 24 words hold the 32 bytes needed by `SeedSize`
+const seedMnemonicWords = 24
+
+// MnemonicFromKey - returns the 24 word BIP-39 mnemonic of an ED25519 private key for paper backups
+func MnemonicFromKey(k Key) (words string, err error) {
+
+	if k.KeyType() != ED25519 || !k.IsPrivateKey() {
+		return "", fmt.Errorf("mnemonicfromkey: only ED25519 private keys are supported, got %s", k.KeyType())
+	}
+
+	priv, ok := k.PrivateKeyInstance().(ed25519.PrivateKey)
+	if !ok {
+		return "", errors.New("mnemonicfromkey: unable to get ED25519 private key")
+	}
+
+	words, err = mnemonic.New(priv.Seed())
+	if nil != err {
+		return "", fmt.Errorf("mnemonicfromkey: %w", err)
+	}
+
+	return
+}
+
+// NewKeyFromMnemonic - returns new instance of an ED25519 private key restored from its BIP-39 mnemonic
+func NewKeyFromMnemonic(kt shared.KeyType, words string) (k Key, err error) {
+
+	if kt != ED25519 {
+		return nil, fmt.Errorf("newkeyfrommnemonic: only ED25519 keys are supported, got %s", kt)
+	}
+
+	seed, err := mnemonic.Entropy(words)
+	if nil != err {
+		return nil, fmt.Errorf("newkeyfrommnemonic: %w", err)
+	}
+	defer clear(seed)
+
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("newkeyfrommnemonic: mnemonic holds %d bytes, expected %d for ED25519", len(seed), ed25519.SeedSize)
+	}
+
+	k, err = ed.New(ed25519.NewKeyFromSeed(seed))
+	if nil != err {
+		return nil, fmt.Errorf("newkeyfrommnemonic: %w", err)
+	}
+
+	return
+}
+
+// MnemonicFromKX - returns the 24 word BIP-39 mnemonic of a CURVE25519 private key exchange for paper backups
+func MnemonicFromKX(kx KeyExchange) (words string, err error) {
+
+	if kx.KeyType() != CURVE25519 || !kx.IsPrivateKey() {
+		return "", fmt.Errorf("mnemonicfromkx: only CURVE25519 private key exchanges are supported, got %s", kx.KeyType())
+	}
+
+	kxBytes, err := kx.Bytes()
+	if nil != err {
+		return "", fmt.Errorf("mnemonicfromkx: %w", err)
+	}
+	defer clear(kxBytes)
+
+	words, err = mnemonic.New(kxBytes[1:]) // the first byte identifies the key exchange type
+	if nil != err {
+		return "", fmt.Errorf("mnemonicfromkx: %w", err)
+	}
+
+	return
+}
+
+// NewKXFromMnemonic - returns new instance of a CURVE25519 private key exchange restored from its BIP-39 mnemonic
+func NewKXFromMnemonic(kxt shared.KeyXType, words string) (kx KeyExchange, err error) {
+
+	if kxt != CURVE25519 {
+		return nil, fmt.Errorf("newkxfrommnemonic: only CURVE25519 key exchanges are supported, got %s", kxt)
+	}
+
+	priv, err := mnemonic.Entropy(words)
+	if nil != err {
+		return nil, fmt.Errorf("newkxfrommnemonic: %w", err)
+	}
+	defer clear(priv)
+
+	kxBytes := append([]byte{crv.TypeCrvPriv}, priv...)
+	defer clear(kxBytes)
+
+	kx, err = crv.New(kxBytes)
+	if nil != err {
+		return nil, fmt.Errorf("newkxfrommnemonic: %w", err)
+	}
+
+	return
+}
+
+// GenerateKeyFromMnemonic - derives a key from a master seed backed up as a 24 word BIP-39 mnemonic, see `GenerateKeyFromSeed`
+func GenerateKeyFromMnemonic(kt shared.KeyType, words string, label string) (k Key, err error) {
+
+	// shorter mnemonics are valid BIP-39 but hold less than `SeedSize` bytes
+	if n := len(strings.Fields(words)); n != seedMnemonicWords {
+		return nil, fmt.Errorf("generatekeyfrommnemonic: mnemonic has %d words, a master seed needs %d", n, seedMnemonicWords)
+	}
+
+	seed, err := mnemonic.Entropy(words)
+	if nil != err {
+		return nil, fmt.Errorf("generatekeyfrommnemonic: %w", err)
+	}
+	defer clear(seed)
+
+	return GenerateKeyFromSeed(kt, seed, label)
+}
+
+// GenerateKeyExchangeFromMnemonic - derives a key exchange from a master seed backed up as a 24 word BIP-39 mnemonic, see `GenerateKeyExchangeFromSeed`
+func GenerateKeyExchangeFromMnemonic(kxt shared.KeyXType, words string, label string) (kx KeyExchange, err error) {
+
+	// shorter mnemonics are valid BIP-39 but hold less than `SeedSize` bytes
+	if n := len(strings.Fields(words)); n != seedMnemonicWords {
+		return nil, fmt.Errorf("generatekeyexchangefrommnemonic: mnemonic has %d words, a master seed needs %d", n, seedMnemonicWords)
+	}
+
+	seed, err := mnemonic.Entropy(words)
+	if nil != err {
+		return nil, fmt.Errorf("generatekeyexchangefrommnemonic: %w", err)
+	}
+	defer clear(seed)
+
+	return GenerateKeyExchangeFromSeed(kxt, seed, label)
+}
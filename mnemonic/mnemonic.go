@@ -0,0 +1,101 @@
+package mnemonic
+
+import (
+	"crypto/sha256"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+//go:embed english.txt
+var english string
+
+// wordlist - BIP-39 English wordlist, https://github.com/bitcoin/bips/blob/master/bip-0039/english.txt
+var wordlist = strings.Fields(english)
+
+// wordIndex - position of each word in the wordlist for decoding
+var wordIndex = func() (m map[string]int) {
+	m = make(map[string]int, len(wordlist))
+	for i, w := range wordlist {
+		m[w] = i
+	}
+	return
+}()
+
+// ErrChecksum - returned when the words of a mnemonic are valid but its checksum does not match
+var ErrChecksum = errors.New("mnemonic checksum mismatch")
+
+// New - returns the BIP-39 mnemonic for the given entropy, which must be 16 to 32 bytes in steps of 4 (12 to 24 words)
+func New(entropy []byte) (words string, err error) {
+
+	if len(entropy) < 16 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return "", fmt.Errorf("mnemonic-new: invalid entropy length %d, expected 16, 20, 24, 28 or 32 bytes", len(entropy))
+	}
+
+	csBits := len(entropy) / 4 // one checksum bit for every 32 bits of entropy
+	count := (len(entropy)*8 + csBits) / 11
+
+	// append the checksum bits taken from the start of the SHA-256 of the entropy
+	sum := sha256.Sum256(entropy)
+	b := new(big.Int).SetBytes(entropy)
+	b.Lsh(b, uint(csBits))
+	b.Or(b, big.NewInt(int64(sum[0]>>(8-csBits))))
+
+	// every 11 bits selects one word, starting from the end
+	out := make([]string, count)
+	mask := big.NewInt(2047)
+	idx := new(big.Int)
+	for i := count - 1; i >= 0; i-- {
+		idx.And(b, mask)
+		out[i] = wordlist[idx.Int64()]
+		b.Rsh(b, 11)
+	}
+
+	return strings.Join(out, " "), nil
+}
+
+// Entropy - returns the entropy encoded by a BIP-39 mnemonic after checking its words and checksum
+func Entropy(words string) (entropy []byte, err error) {
+
+	fields := strings.Fields(strings.ToLower(words))
+
+	switch len(fields) {
+	case 12, 15, 18, 21, 24:
+	default:
+		return nil, fmt.Errorf("mnemonic-entropy: invalid number of words %d, expected 12, 15, 18, 21 or 24", len(fields))
+	}
+
+	b := new(big.Int)
+	for i, w := range fields {
+		idx, ok := wordIndex[w]
+		if !ok {
+			return nil, fmt.Errorf("mnemonic-entropy: word %d %q is not in the wordlist", i+1, w)
+		}
+		b.Lsh(b, 11)
+		b.Or(b, big.NewInt(int64(idx)))
+	}
+
+	csBits := len(fields) / 3
+	size := (len(fields)*11 - csBits) / 8
+
+	cs := new(big.Int).And(b, big.NewInt(int64(1<<csBits-1)))
+	b.Rsh(b, uint(csBits))
+
+	entropy = b.FillBytes(make([]byte, size))
+
+	sum := sha256.Sum256(entropy)
+	if cs.Int64() != int64(sum[0]>>(8-csBits)) {
+		clear(entropy)
+		return nil, fmt.Errorf("mnemonic-entropy: %w", ErrChecksum)
+	}
+
+	return
+}
+
+// Valid - returns if the words form a valid BIP-39 mnemonic
+func Valid(words string) (ok bool) {
+	_, err := Entropy(words)
+	return nil == err
+}
@@ -0,0 +1,122 @@
+package key
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/svicknesh/key/v2/shared"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// PasswordKDF - function used to stretch a password into a seed
+type PasswordKDF uint8
+
+const (
+	// Argon2id - memory hard password hashing (RFC 9106), recommended
+	Argon2id PasswordKDF = iota + 1
+
+	// Scrypt - memory hard password hashing (RFC 7914)
+	Scrypt
+)
+
+// MinSaltSize - minimum size in bytes of the salt used for password derivation
+const MinSaltSize = 16
+
+// passwordLabel - label separating password derived keys from keys derived directly from a seed
+const passwordLabel = "password"
+
+// PasswordParams - parameters for deriving keys from a password, the same parameters and salt must be used to derive the same key again
+type PasswordParams struct {
+	KDF PasswordKDF
+
+	// Argon2id parameters, `Memory` is in KiB
+	Time    uint32
+	Memory  uint32
+	Threads uint8
+
+	// scrypt parameters, `N` must be a power of 2
+	N int
+	R int
+	P int
+}
+
+// DefaultArgon2idParams - returns the second recommended Argon2id option of RFC 9106 (3 passes, 64 MiB, 4 lanes)
+func DefaultArgon2idParams() (params PasswordParams) {
+	return PasswordParams{KDF: Argon2id, Time: 3, Memory: 64 * 1024, Threads: 4}
+}
+
+// DefaultScryptParams - returns the scrypt parameters recommended for interactive logins (N=32768, r=8, p=1)
+func DefaultScryptParams() (params PasswordParams) {
+	return PasswordParams{KDF: Scrypt, N: 1 << 15, R: 8, P: 1}
+}
+
+// DeriveKeyFromPassword - derives a key from a password and salt, the same password, salt and parameters always produce the same key.
+// RSA keys cannot be derived deterministically and are not supported.
+func DeriveKeyFromPassword(kt shared.KeyType, password, salt []byte, params PasswordParams) (k shared.Key, err error) {
+
+	seed, err := StretchPassword(password, salt, params)
+	if nil != err {
+		return nil, fmt.Errorf("derivekeyfrompassword: %w", err)
+	}
+	defer clear(seed)
+
+	k, err = GenerateKeyFromSeed(kt, seed, passwordLabel)
+	if nil != err {
+		return nil, fmt.Errorf("derivekeyfrompassword: %w", err)
+	}
+
+	return
+}
+
+// DeriveKeyExchangeFromPassword - derives a key exchange from a password and salt, the same password, salt and parameters always produce the same key exchange
+func DeriveKeyExchangeFromPassword(kxt shared.KeyXType, password, salt []byte, params PasswordParams) (kx shared.KeyExchange, err error) {
+
+	seed, err := StretchPassword(password, salt, params)
+	if nil != err {
+		return nil, fmt.Errorf("derivekeyexchangefrompassword: %w", err)
+	}
+	defer clear(seed)
+
+	kx, err = GenerateKeyExchangeFromSeed(kxt, seed, passwordLabel)
+	if nil != err {
+		return nil, fmt.Errorf("derivekeyexchangefrompassword: %w", err)
+	}
+
+	return
+}
+
+// StretchPassword - stretches the password into a `SeedSize` byte secret using the chosen function, for use as a master seed or encryption key
+func StretchPassword(password, salt []byte, params PasswordParams) (seed []byte, err error) {
+
+	if err = shared.CheckFIPS("password based key derivation using Argon2id or scrypt"); nil != err {
+		return nil, fmt.Errorf("stretchpassword: %w", err)
+	}
+
+	if len(password) == 0 {
+		return nil, errors.New("stretchpassword: empty password")
+	}
+
+	if len(salt) < MinSaltSize {
+		return nil, fmt.Errorf("stretchpassword: salt too short (%d bytes), need at least %d", len(salt), MinSaltSize)
+	}
+
+	switch params.KDF {
+	case Argon2id:
+		// argon2 panics on invalid parameters instead of returning an error
+		if params.Time < 1 || params.Threads < 1 || params.Memory < 8*uint32(params.Threads) {
+			return nil, fmt.Errorf("stretchpassword: invalid argon2id parameters time=%d memory=%d threads=%d", params.Time, params.Memory, params.Threads)
+		}
+		return argon2.IDKey(password, salt, params.Time, params.Memory, params.Threads, SeedSize), nil
+
+	case Scrypt:
+		seed, err = scrypt.Key(password, salt, params.N, params.R, params.P, SeedSize)
+		if nil != err {
+			return nil, fmt.Errorf("stretchpassword: scrypt error -> %w", err)
+		}
+		return
+
+	}
+
+	return nil, fmt.Errorf("stretchpassword: unknown password derivation function %d", params.KDF)
+}
@@ -0,0 +1,317 @@
+package rotation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/svicknesh/key/v2"
+	"github.com/svicknesh/key/v2/shared"
+)
+
+// ErrUnknownKeyID - returned when no active, next or previous key has the requested `kid`
+var ErrUnknownKeyID = errors.New("unknown key id")
+
+// Config - settings of a `Manager`
+type Config struct {
+	KeyType     shared.KeyType
+	Interval    time.Duration    // how long a key is active before `RotateIfDue` rotates it, 0 only rotates on demand
+	GracePeriod time.Duration    // how long a previous key keeps verifying after it was rotated out
+	Options     []shared.Option  // passed to `key.GenerateKey`, for example `key.WithPolicy`
+	Now         func() time.Time // clock used for rotation and by the generated keys to enforce their validity, defaults to `time.Now`
+}
+
+// generation - key and the times it changed state
+type generation struct {
+	k         shared.Key
+	kid       string
+	activated time.Time // zero while it is the next key
+	retired   time.Time // zero until it is rotated out
+	expires   time.Time // published `exp` once it is rotated out, the end of its grace period unless it expires earlier
+}
+
+// Manager - keeps the next key published before it is used, the active key used for signing and the previous keys still accepted for verification
+type Manager struct {
+	mu       sync.RWMutex
+	cfg      Config
+	next     *generation
+	active   *generation
+	previous []*generation // most recently retired first
+}
+
+// New - returns a manager with a newly generated active and next key
+func New(cfg Config) (m *Manager, err error) {
+
+	if cfg.Interval < 0 || cfg.GracePeriod < 0 {
+		return nil, errors.New("rotation-new: interval and grace period cannot be negative")
+	}
+
+	if nil == cfg.Now {
+		cfg.Now = time.Now
+	}
+
+	m = &Manager{cfg: cfg}
+
+	if m.active, err = m.generate(); nil != err {
+		return nil, fmt.Errorf("rotation-new: %w", err)
+	}
+	m.active.activated = cfg.Now()
+
+	if m.next, err = m.generate(); nil != err {
+		return nil, fmt.Errorf("rotation-new: %w", err)
+	}
+
+	return
+}
+
+// Rotate - makes the next key active, keeps the active key for verification during the grace period and generates a new next key
+func (m *Manager) Rotate() (err error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err = m.rotate(); nil != err {
+		return fmt.Errorf("rotation-rotate: %w", err)
+	}
+
+	return
+}
+
+// RotateIfDue - rotates when the active key has been active for the configured interval and drops previous keys past their grace period
+func (m *Manager) RotateIfDue() (rotated bool, err error) {
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.cfg.Now()
+	m.prune(now)
+
+	if m.cfg.Interval == 0 || now.Before(m.active.activated.Add(m.cfg.Interval)) {
+		return false, nil
+	}
+
+	if err = m.rotate(); nil != err {
+		return false, fmt.Errorf("rotation-rotateifdue: %w", err)
+	}
+
+	return true, nil
+}
+
+// Run - calls `RotateIfDue` every `check` until the context is done or rotation fails, `check` must be positive
+func (m *Manager) Run(ctx context.Context, check time.Duration) (err error) {
+
+	if check <= 0 {
+		return fmt.Errorf("rotation-run: check interval must be positive, got %s", check)
+	}
+
+	t := time.NewTicker(check)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			if _, err = m.RotateIfDue(); nil != err {
+				return
+			}
+		}
+	}
+}
+
+// Active - returns the key used for signing
+func (m *Manager) Active() (k shared.Key) {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.active.k
+}
+
+// Next - returns the key that becomes active on the next rotation, its public key is already published in the JWKS
+func (m *Manager) Next() (k shared.Key) {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.next.k
+}
+
+// Previous - returns the rotated out keys still within their grace period, most recently retired first
+func (m *Manager) Previous() (ks []shared.Key) {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := m.cfg.Now()
+	for _, g := range m.previous {
+		if m.inGrace(g, now) {
+			ks = append(ks, g.k)
+		}
+	}
+
+	return
+}
+
+// SignMessage - signs the message using the active key, returning its `kid` so verifiers can select the key
+func (m *Manager) SignMessage(msg []byte, opts ...shared.Option) (kid string, signed []byte, err error) {
+
+	// the read lock keeps the active key from being retired while it signs
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	active := m.active
+	signed, err = active.k.SignMessage(msg, opts...)
+	if nil != err {
+		return "", nil, fmt.Errorf("rotation-signmessage: %w", err)
+	}
+
+	return active.kid, signed, nil
+}
+
+// Verifier - returns the public key for an incoming `kid`, which may be the next, active or a previous key within its grace period
+func (m *Manager) Verifier(kid string) (kPub shared.Key, err error) {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := m.cfg.Now()
+	for _, g := range m.generations() {
+		if g.kid != kid || !m.inGrace(g, now) {
+			continue
+		}
+
+		return m.publicKey(g)
+	}
+
+	return nil, fmt.Errorf("rotation-verifier: %w %q", ErrUnknownKeyID, kid)
+}
+
+// JWKS - returns the public keys of the next, active and previous keys as a JSON Web Key Set
+func (m *Manager) JWKS() (jwks []byte, err error) {
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	set := struct {
+		Keys []json.RawMessage `json:"keys"`
+	}{}
+
+	now := m.cfg.Now()
+	for _, g := range m.generations() {
+		if !m.inGrace(g, now) {
+			continue
+		}
+
+		kPub, err := m.publicKey(g)
+		if nil != err {
+			return nil, fmt.Errorf("rotation-jwks: %w", err)
+		}
+
+		b, err := kPub.Bytes()
+		if nil != err {
+			return nil, fmt.Errorf("rotation-jwks: %w", err)
+		}
+
+		set.Keys = append(set.Keys, b)
+	}
+
+	return json.Marshal(set)
+}
+
+// generate - generates a key with a fixed `kid`
+func (m *Manager) generate() (g *generation, err error) {
+
+	// the keys check the validity set on rotation against the same clock
+	k, err := key.GenerateKey(m.cfg.KeyType, append(slices.Clone(m.cfg.Options), key.WithClock(m.cfg.Now))...)
+	if nil != err {
+		return
+	}
+
+	// the generated key ID is the JWK thumbprint, it is read back once so it is not computed again
+	meta := struct {
+		KeyID string `json:"kid"`
+	}{}
+	b, err := k.Bytes()
+	if nil == err {
+		err = json.Unmarshal(b, &meta)
+	}
+	if nil != err {
+		return nil, fmt.Errorf("error reading key id -> %w", err)
+	}
+	k.SetKeyID(meta.KeyID)
+
+	return &generation{k: k, kid: meta.KeyID}, nil
+}
+
+// rotate - moves every key one state along, the caller must hold the write lock
+func (m *Manager) rotate() (err error) {
+
+	next, err := m.generate()
+	if nil != err {
+		return
+	}
+
+	now := m.cfg.Now()
+
+	// the retired key can no longer sign, verifiers are told it expires at the end of the grace period since its signatures are accepted until then
+	retired := m.active
+	retired.retired = now
+	v := retired.k.Validity()
+	retired.expires = now.Add(m.cfg.GracePeriod)
+	if !v.NotAfter.IsZero() && v.NotAfter.Before(retired.expires) {
+		retired.expires = v.NotAfter
+	}
+	v.NotAfter = now
+	retired.k.SetValidity(v)
+
+	m.previous = append([]*generation{retired}, m.previous...)
+	m.active, m.next = m.next, next
+	m.active.activated = now
+	m.prune(now)
+
+	return
+}
+
+// prune - drops previous keys past their grace period, the caller must hold the write lock.
+// The keys are not destroyed since callers of `Active` or `Previous` may still hold them, they are released for garbage collection instead.
+func (m *Manager) prune(now time.Time) {
+
+	kept := m.previous[:0]
+	for _, g := range m.previous {
+		if m.inGrace(g, now) {
+			kept = append(kept, g)
+		}
+	}
+
+	clear(m.previous[len(kept):])
+	m.previous = kept
+}
+
+// publicKey - returns the public key of a generation, a retired key has the `exp` of its grace period instead of the end of its signing validity
+func (m *Manager) publicKey(g *generation) (kPub shared.Key, err error) {
+
+	if kPub, err = g.k.PublicKey(); nil != err || g.retired.IsZero() {
+		return
+	}
+
+	v := kPub.Validity()
+	v.NotAfter = g.expires
+	kPub.SetValidity(v)
+
+	return
+}
+
+// inGrace - returns if a key may still be used for verification
+func (m *Manager) inGrace(g *generation, now time.Time) (ok bool) {
+	return g.retired.IsZero() || now.Before(g.retired.Add(m.cfg.GracePeriod))
+}
+
+// generations - returns the next, active and previous keys in that order
+func (m *Manager) generations() (gs []*generation) {
+	return append([]*generation{m.next, m.active}, m.previous...)
+}
@@ -0,0 +1,165 @@
+package key
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"fmt"
+	"io"
+
+	"github.com/svicknesh/key/v2/asym/ec"
+	"github.com/svicknesh/key/v2/asym/ed"
+	"github.com/svicknesh/key/v2/kx/crv"
+	"github.com/svicknesh/key/v2/kx/ecdhc"
+	"github.com/svicknesh/key/v2/shared"
+)
+
+const (
+	// SeedSize - minimum size in bytes of the master seed used for deterministic key derivation
+	SeedSize = 32
+
+	// seedSalt - HKDF salt separating keys derived by this library from other uses of the same seed
+	seedSalt = "github.com/svicknesh/key/v2 seed derivation"
+
+	// seedStreamSize - longest HKDF-SHA256 output, enough for every attempt of rejection sampling the largest scalar
+	seedStreamSize = 255 * sha256.Size
+)
+
+// GenerateKeyFromSeed - derives a key deterministically from a master seed, the same seed, key type and label always produce the same key.
+// RSA keys cannot be derived deterministically and are not supported.
+func GenerateKeyFromSeed(kt shared.KeyType, seed []byte, label string) (k shared.Key, err error) {
+
+	if err = shared.CheckFIPS("deterministic key derivation from a seed"); nil != err {
+		return nil, fmt.Errorf("generatekeyfromseed: %w", err)
+	}
+
+	rd, err := seedReader(seed, "key", kt.String(), label)
+	if nil != err {
+		return nil, fmt.Errorf("generatekeyfromseed: %w", err)
+	}
+
+	// the private key is built from the HKDF output directly, so it does not depend on how key generation consumes randomness
+	switch kt {
+	case ED25519:
+		b := make([]byte, ed25519.SeedSize)
+		defer clear(b)
+		if _, err = io.ReadFull(rd, b); nil != err {
+			return nil, fmt.Errorf("generatekeyfromseed: %w", err)
+		}
+		k, err = ed.New(ed25519.NewKeyFromSeed(b))
+
+	case ECDSA256, ECDSA384, ECDSA521:
+		var priv *ecdsa.PrivateKey
+		curve, size, mask := seedCurve(kt)
+		err = shared.SampleScalar(rd, size, mask, func(b []byte) (err error) {
+			priv, err = ecdsa.ParseRawPrivateKey(curve, b)
+			return
+		})
+		if nil == err {
+			k, err = ec.New(priv)
+		}
+
+	default:
+		return nil, fmt.Errorf("generatekeyfromseed: unsupported key type %s for seed derivation", kt)
+	}
+
+	if nil != err {
+		return nil, fmt.Errorf("generatekeyfromseed: %w", err)
+	}
+
+	return
+}
+
+// GenerateKeyExchangeFromSeed - derives a key exchange deterministically from a master seed, the same seed, key exchange type and label always produce the same key exchange
+func GenerateKeyExchangeFromSeed(kxt shared.KeyXType, seed []byte, label string) (kx shared.KeyExchange, err error) {
+
+	if err = shared.CheckFIPS("deterministic key derivation from a seed"); nil != err {
+		return nil, fmt.Errorf("generatekeyexchangefromseed: %w", err)
+	}
+
+	rd, err := seedReader(seed, "kx", kxt.String(), label)
+	if nil != err {
+		return nil, fmt.Errorf("generatekeyexchangefromseed: %w", err)
+	}
+
+	// the private key is built from the HKDF output directly, so it does not depend on how key generation consumes randomness
+	switch kxt {
+	case CURVE25519:
+		b := make([]byte, 32)
+		defer clear(b)
+		if _, err = io.ReadFull(rd, b); nil != err {
+			return nil, fmt.Errorf("generatekeyexchangefromseed: %w", err)
+		}
+		kx, err = crv.New(append([]byte{crv.TypeCrvPriv}, b...))
+
+	case ECDH256, ECDH384, ECDH521:
+		var priv *ecdh.PrivateKey
+		curve, id, size, mask := seedECDHCurve(kxt)
+		err = shared.SampleScalar(rd, size, mask, func(b []byte) (err error) {
+			priv, err = curve.NewPrivateKey(b)
+			return
+		})
+		if nil == err {
+			kx, err = ecdhc.New(append([]byte{id}, priv.Bytes()...))
+		}
+
+	default:
+		return nil, fmt.Errorf("generatekeyexchangefromseed: unsupported key exchange type %s for seed derivation", kxt)
+	}
+
+	if nil != err {
+		return nil, fmt.Errorf("generatekeyexchangefromseed: %w", err)
+	}
+
+	return
+}
+
+// seedCurve - returns the curve, scalar size and first byte mask used to sample an ECDSA private key
+func seedCurve(kt shared.KeyType) (curve elliptic.Curve, size int, mask byte) {
+	switch kt {
+	case ECDSA384:
+		return elliptic.P384(), 48, 0xff
+	case ECDSA521:
+		return elliptic.P521(), 66, 0x01 // P-521 scalars only use the lowest bit of the first byte
+	}
+	return elliptic.P256(), 32, 0xff
+}
+
+// seedECDHCurve - returns the curve, private key identifier, scalar size and first byte mask used to sample an ECDH private key
+func seedECDHCurve(kxt shared.KeyXType) (curve ecdh.Curve, id uint8, size int, mask byte) {
+	switch kxt {
+	case ECDH384:
+		return ecdh.P384(), ecdhc.TypeECDHPriv384, 48, 0xff
+	case ECDH521:
+		return ecdh.P521(), ecdhc.TypeECDHPriv521, 66, 0x01 // P-521 scalars only use the lowest bit of the first byte
+	}
+	return ecdh.P256(), ecdhc.TypeECDHPriv256, 32, 0xff
+}
+
+// seedReader - returns an HKDF-SHA256 stream bound to the family, type and label so each derived key is independent
+func seedReader(seed []byte, family, typ, label string) (rd io.Reader, err error) {
+
+	if len(seed) < SeedSize {
+		return nil, fmt.Errorf("seed too short (%d bytes), need at least %d", len(seed), SeedSize)
+	}
+
+	// the label is written last and length prefixed so no two (type, label) pairs share the same info
+	info := fmt.Sprintf("%s|%s|%d|%s", family, typ, len(label), label)
+
+	prk, err := hkdf.Extract(sha256.New, seed, []byte(seedSalt))
+	if nil != err {
+		return
+	}
+	defer clear(prk)
+
+	stream, err := hkdf.Expand(sha256.New, prk, info, seedStreamSize)
+	if nil != err {
+		return
+	}
+
+	return bytes.NewReader(stream), nil
+}
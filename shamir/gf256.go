@@ -0,0 +1,48 @@
+package shamir
+
+// arithmetic in GF(2^8) with the AES polynomial x^8 + x^4 + x^3 + x + 1, without lookup tables so the time taken does not depend on the secret
+
+// gfMul - multiplies two field elements
+func gfMul(a, b byte) (p byte) {
+	for range 8 {
+		p ^= -(b & 1) & a       // add a when the lowest bit of b is set
+		a = a<<1 ^ -(a>>7)&0x1b // multiply a by x, reducing when it overflows
+		b >>= 1
+	}
+	return
+}
+
+// gfInv - returns the multiplicative inverse as a^254, the inverse of 0 is 0
+func gfInv(a byte) (inv byte) {
+	// a^254 = a^(2+4+8+16+32+64+128)
+	inv = 1
+	sq := a
+	for range 7 {
+		sq = gfMul(sq, sq)
+		inv = gfMul(inv, sq)
+	}
+	return
+}
+
+// evaluate - evaluates the polynomial with the given coefficients, lowest degree first, at x
+func evaluate(coeffs []byte, x byte) (y byte) {
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ coeffs[i]
+	}
+	return
+}
+
+// interpolate - returns the value at x of the polynomial passing through the points (xs[i], ys[i]), the secret is the value at 0
+func interpolate(xs, ys []byte, x byte) (y byte) {
+	for i := range xs {
+		// Lagrange basis polynomial for point i evaluated at x, in GF(2^8) subtraction is addition
+		basis := byte(1)
+		for j := range xs {
+			if i != j {
+				basis = gfMul(basis, gfMul(x^xs[j], gfInv(xs[i]^xs[j])))
+			}
+		}
+		y ^= gfMul(ys[i], basis)
+	}
+	return
+}
@@ -0,0 +1,369 @@
+package shamir
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"github.com/svicknesh/key/v2"
+	"github.com/svicknesh/key/v2/shared"
+)
+
+var (
+	// ErrInvalidShare - returned when a share is malformed or its checksum does not match
+	ErrInvalidShare = errors.New("invalid share")
+
+	// ErrNotEnoughShares - returned when fewer shares than the threshold are combined
+	ErrNotEnoughShares = errors.New("not enough shares")
+
+	// ErrMismatchedShares - returned when shares from different splits or of different keys are combined
+	ErrMismatchedShares = errors.New("shares do not belong together")
+)
+
+const (
+	shareVersion = 1
+
+	kindKey         = 1
+	kindKeyExchange = 2
+
+	idSize          = 4
+	fingerprintSize = 8
+	checksumSize    = 4
+	headerSize      = 5 + idSize + fingerprintSize // version, kind, type, threshold, index, split ID, public key fingerprint
+)
+
+// Share - one of the shares a private key is split into, any `Threshold` shares of the same split reconstruct the key
+type Share struct {
+	KeyType   shared.KeyType  // set when a key was split
+	KeyXType  shared.KeyXType // set when a key exchange was split
+	Threshold uint8
+	Index     uint8 // x coordinate of the share, from 1 to the number of shares
+
+	id          [idSize]byte          // random identifier shared by every share of a split
+	fingerprint [fingerprintSize]byte // of the public key, verifies the reconstructed key
+	payload     []byte
+}
+
+// SplitKey - splits the private key into `n` shares, any `threshold` of them reconstruct it with `CombineKey`
+func SplitKey(k shared.Key, n, threshold int) (shares []*Share, err error) {
+
+	if nil == k || !k.IsPrivateKey() {
+		return nil, errors.New("shamir-splitkey: a private key is required")
+	}
+
+	material, err := k.Bytes()
+	if nil != err {
+		return nil, fmt.Errorf("shamir-splitkey: %w", err)
+	}
+	defer clear(material)
+
+	if shares, err = split(material, n, threshold); nil != err {
+		return nil, fmt.Errorf("shamir-splitkey: %w", err)
+	}
+
+	fp, err := keyFingerprint(shares[0].id, k)
+	if nil != err {
+		return nil, fmt.Errorf("shamir-splitkey: %w", err)
+	}
+	for _, s := range shares {
+		s.KeyType, s.fingerprint = k.KeyType(), fp
+	}
+
+	return
+}
+
+// SplitKeyExchange - splits the private key exchange into `n` shares, any `threshold` of them reconstruct it with `CombineKeyExchange`
+func SplitKeyExchange(kx shared.KeyExchange, n, threshold int) (shares []*Share, err error) {
+
+	if nil == kx || !kx.IsPrivateKey() {
+		return nil, errors.New("shamir-splitkeyexchange: a private key exchange is required")
+	}
+
+	material, err := kx.Bytes()
+	if nil != err {
+		return nil, fmt.Errorf("shamir-splitkeyexchange: %w", err)
+	}
+	defer clear(material)
+
+	if shares, err = split(material, n, threshold); nil != err {
+		return nil, fmt.Errorf("shamir-splitkeyexchange: %w", err)
+	}
+
+	fp := keyExchangeFingerprint(shares[0].id, kx)
+	for _, s := range shares {
+		s.KeyXType, s.fingerprint = kx.KeyType(), fp
+	}
+
+	return
+}
+
+// CombineKey - reconstructs a key from at least `Threshold` shares returned by `SplitKey`
+func CombineKey(shares []*Share) (k shared.Key, err error) {
+
+	if len(shares) > 0 && shares[0].KeyType == 0 {
+		return nil, fmt.Errorf("shamir-combinekey: %w, shares are of a key exchange", ErrMismatchedShares)
+	}
+
+	material, err := combine(shares)
+	if nil != err {
+		return nil, fmt.Errorf("shamir-combinekey: %w", err)
+	}
+	defer clear(material)
+
+	if k, err = key.NewKeyFromBytes(material, key.WithStrict()); nil != err {
+		return nil, fmt.Errorf("shamir-combinekey: %w, reconstructed key is invalid -> %w", ErrInvalidShare, err)
+	}
+	if k.KeyType() != shares[0].KeyType {
+		k.Destroy()
+		return nil, fmt.Errorf("shamir-combinekey: %w, reconstructed %s key, expected %s", ErrInvalidShare, k.KeyType(), shares[0].KeyType)
+	}
+
+	fp, err := keyFingerprint(shares[0].id, k)
+	if nil == err && fp != shares[0].fingerprint {
+		err = fmt.Errorf("%w, reconstructed key does not match", ErrInvalidShare)
+	}
+	if nil != err {
+		k.Destroy()
+		return nil, fmt.Errorf("shamir-combinekey: %w", err)
+	}
+
+	return
+}
+
+// CombineKeyExchange - reconstructs a key exchange from at least `Threshold` shares returned by `SplitKeyExchange`
+func CombineKeyExchange(shares []*Share) (kx shared.KeyExchange, err error) {
+
+	if len(shares) > 0 && shares[0].KeyXType == 0 {
+		return nil, fmt.Errorf("shamir-combinekeyexchange: %w, shares are of a key", ErrMismatchedShares)
+	}
+
+	material, err := combine(shares)
+	if nil != err {
+		return nil, fmt.Errorf("shamir-combinekeyexchange: %w", err)
+	}
+	defer clear(material)
+
+	if kx, err = key.NewKXFromBytes(material, key.WithStrict()); nil != err {
+		return nil, fmt.Errorf("shamir-combinekeyexchange: %w, reconstructed key exchange is invalid -> %w", ErrInvalidShare, err)
+	}
+	if kx.KeyType() != shares[0].KeyXType {
+		kx.Destroy()
+		return nil, fmt.Errorf("shamir-combinekeyexchange: %w, reconstructed %s key exchange, expected %s", ErrInvalidShare, kx.KeyType(), shares[0].KeyXType)
+	}
+	if keyExchangeFingerprint(shares[0].id, kx) != shares[0].fingerprint {
+		kx.Destroy()
+		return nil, fmt.Errorf("shamir-combinekeyexchange: %w, reconstructed key exchange does not match", ErrInvalidShare)
+	}
+
+	return
+}
+
+// NewShareFromBytes - parses a share encoded by `Bytes`, checking its checksum
+func NewShareFromBytes(b []byte) (s *Share, err error) {
+
+	if len(b) < headerSize+1+checksumSize {
+		return nil, fmt.Errorf("newsharefrombytes: %w, too short", ErrInvalidShare)
+	}
+
+	body, sum := b[:len(b)-checksumSize], b[len(b)-checksumSize:]
+	if expected := checksum(body); subtle.ConstantTimeCompare(sum, expected[:]) != 1 {
+		return nil, fmt.Errorf("newsharefrombytes: %w, checksum mismatch", ErrInvalidShare)
+	}
+
+	if body[0] != shareVersion {
+		return nil, fmt.Errorf("newsharefrombytes: %w, unsupported version %d", ErrInvalidShare, body[0])
+	}
+
+	s = &Share{Threshold: body[3], Index: body[4]}
+	switch body[1] {
+	case kindKey:
+		s.KeyType = shared.KeyType(body[2])
+	case kindKeyExchange:
+		s.KeyXType = shared.KeyXType(body[2])
+	default:
+		return nil, fmt.Errorf("newsharefrombytes: %w, unknown kind %d", ErrInvalidShare, body[1])
+	}
+	if s.Threshold < 2 || s.Index == 0 {
+		return nil, fmt.Errorf("newsharefrombytes: %w, threshold %d and index %d", ErrInvalidShare, s.Threshold, s.Index)
+	}
+
+	copy(s.id[:], body[5:])
+	copy(s.fingerprint[:], body[5+idSize:])
+	s.payload = append([]byte(nil), body[headerSize:]...)
+
+	return
+}
+
+// NewShareFromStr - parses a share encoded by `String`
+func NewShareFromStr(str string) (s *Share, err error) {
+
+	b, err := base64.URLEncoding.DecodeString(str)
+	if nil != err {
+		return nil, fmt.Errorf("newsharefromstr: %w, %w", ErrInvalidShare, err)
+	}
+
+	return NewShareFromBytes(b)
+}
+
+// Bytes - returns the binary encoding of the share, which includes the key type and a checksum
+func (s *Share) Bytes() (b []byte) {
+
+	b = make([]byte, 0, headerSize+len(s.payload)+checksumSize)
+
+	if s.KeyXType != 0 {
+		b = append(b, shareVersion, kindKeyExchange, byte(s.KeyXType))
+	} else {
+		b = append(b, shareVersion, kindKey, byte(s.KeyType))
+	}
+	b = append(b, s.Threshold, s.Index)
+	b = append(b, s.id[:]...)
+	b = append(b, s.fingerprint[:]...)
+	b = append(b, s.payload...)
+
+	sum := checksum(b)
+
+	return append(b, sum[:]...)
+}
+
+// String - returns the share as base64 URL encoded string
+func (s *Share) String() (str string) {
+	return base64.URLEncoding.EncodeToString(s.Bytes())
+}
+
+// Destroy - clears the share from memory
+func (s *Share) Destroy() {
+	clear(s.payload)
+	s.payload = nil
+}
+
+// split - splits the secret byte by byte, each byte is the constant term of a random polynomial of degree `threshold - 1`
+func split(secret []byte, n, threshold int) (shares []*Share, err error) {
+
+	if threshold < 2 || threshold > n || n > 255 {
+		return nil, fmt.Errorf("need 2 <= threshold <= shares <= 255, got threshold %d and %d shares", threshold, n)
+	}
+
+	var id [idSize]byte
+	if _, err = rand.Read(id[:]); nil != err {
+		return
+	}
+
+	shares = make([]*Share, n)
+	for i := range shares {
+		shares[i] = &Share{Threshold: uint8(threshold), Index: uint8(i + 1), id: id, payload: make([]byte, len(secret))}
+	}
+
+	coeffs := make([]byte, threshold)
+	defer clear(coeffs)
+
+	for j, b := range secret {
+		if _, err = rand.Read(coeffs[1:]); nil != err {
+			return nil, err
+		}
+		coeffs[0] = b
+
+		for _, s := range shares {
+			s.payload[j] = evaluate(coeffs, s.Index)
+		}
+	}
+
+	return
+}
+
+// combine - reconstructs the secret from shares of the same split, shares beyond the threshold must lie on the same polynomial
+func combine(shares []*Share) (secret []byte, err error) {
+
+	if len(shares) == 0 {
+		return nil, ErrNotEnoughShares
+	}
+
+	first := shares[0]
+	if len(shares) < int(first.Threshold) {
+		return nil, fmt.Errorf("%w, got %d of %d", ErrNotEnoughShares, len(shares), first.Threshold)
+	}
+
+	seen := make(map[uint8]bool, len(shares))
+	for _, s := range shares {
+		if s.id != first.id || s.fingerprint != first.fingerprint || s.Threshold != first.Threshold || s.KeyType != first.KeyType || s.KeyXType != first.KeyXType || len(s.payload) != len(first.payload) {
+			return nil, ErrMismatchedShares
+		}
+		if s.Index == 0 || seen[s.Index] {
+			return nil, fmt.Errorf("%w, duplicate or zero index %d", ErrInvalidShare, s.Index)
+		}
+		seen[s.Index] = true
+	}
+
+	// exactly `Threshold` shares determine the polynomial, the others are checked against it
+	used, extra := shares[:first.Threshold], shares[first.Threshold:]
+
+	xs := make([]byte, len(used))
+	for i, s := range used {
+		xs[i] = s.Index
+	}
+
+	secret = make([]byte, len(first.payload))
+	ys := make([]byte, len(used))
+	defer clear(ys)
+
+	mismatch := make([]byte, len(extra))
+	for j := range secret {
+		for i, s := range used {
+			ys[i] = s.payload[j]
+		}
+		secret[j] = interpolate(xs, ys, 0)
+
+		for i, s := range extra {
+			mismatch[i] |= interpolate(xs, ys, s.Index) ^ s.payload[j]
+		}
+	}
+
+	for i, m := range mismatch {
+		if m != 0 {
+			clear(secret)
+			return nil, fmt.Errorf("%w, share %d does not match the others", ErrInvalidShare, extra[i].Index)
+		}
+	}
+
+	return
+}
+
+// fingerprint - identifies the public key in every share so the reconstructed key can be verified, it reveals nothing about the private key
+func fingerprint(id [idSize]byte, pub []byte) (fp [fingerprintSize]byte) {
+	h := sha256.New()
+	h.Write([]byte("shamir public key"))
+	h.Write(id[:])
+	h.Write(pub)
+	copy(fp[:], h.Sum(nil))
+	return
+}
+
+// keyFingerprint - fingerprint of the public JWK of the key, which includes its `kid` and validity
+func keyFingerprint(id [idSize]byte, k shared.Key) (fp [fingerprintSize]byte, err error) {
+
+	kPub, err := k.PublicKey()
+	if nil != err {
+		return
+	}
+
+	pub, err := kPub.Bytes()
+	if nil != err {
+		return
+	}
+
+	return fingerprint(id, pub), nil
+}
+
+// keyExchangeFingerprint - fingerprint of the type and public key of the key exchange
+func keyExchangeFingerprint(id [idSize]byte, kx shared.KeyExchange) (fp [fingerprintSize]byte) {
+	return fingerprint(id, append([]byte{byte(kx.KeyType())}, kx.PublicKeyInstance()...))
+}
+
+// checksum - detects transcription errors in an encoded share
+func checksum(b []byte) (sum [checksumSize]byte) {
+	h := sha256.Sum256(b)
+	copy(sum[:], h[:])
+	return
+}
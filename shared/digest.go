@@ -0,0 +1,22 @@
+package shared
+
+import (
+	"fmt"
+	"io"
+)
+
+// Digest - hashes everything read from `rd` using the message digest of the given key type
+func Digest(kt KeyType, rd io.Reader) (digest []byte, err error) {
+
+	hf := kt.Hash()
+	if hf == 0 {
+		return nil, fmt.Errorf("digest: key type %s does not use a message digest", kt)
+	}
+
+	h := hf.New()
+	if _, err = io.Copy(h, rd); nil != err {
+		return nil, fmt.Errorf("digest: error reading message -> %w", err)
+	}
+
+	return h.Sum(nil), nil
+}
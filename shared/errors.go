@@ -0,0 +1,20 @@
+package shared
+
+import "errors"
+
+var (
+	// ErrNoPublicKey - returned when verification is attempted without a public key
+	ErrNoPublicKey = errors.New("no public key exists for verification")
+
+	// ErrMalformedSignature - returned when the signature cannot be decoded for the key type
+	ErrMalformedSignature = errors.New("malformed signature")
+
+	// ErrSignatureMismatch - returned when a well-formed signature does not match the data
+	ErrSignatureMismatch = errors.New("signature does not match")
+
+	// ErrKeyDestroyed - returned when a key or key exchange is used after `Destroy` was called
+	ErrKeyDestroyed = errors.New("key has been destroyed")
+
+	// ErrInvalidKey - returned by `Validate` and strict parsing when the key material is inconsistent or unsafe to use
+	ErrInvalidKey = errors.New("invalid key")
+)
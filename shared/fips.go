@@ -0,0 +1,68 @@
+package shared
+
+import (
+	"crypto/fips140"
+	"errors"
+	"fmt"
+)
+
+// ErrNotFIPSApproved - returned in FIPS 140-3 mode when an algorithm or operation is not approved
+var ErrNotFIPSApproved = errors.New("not approved in FIPS 140-3 mode")
+
+// FIPSMode - returns if the Go Cryptographic Module runs in FIPS 140-3 mode (`GODEBUG=fips140=on` or `only`), the library then refuses algorithms and operations that are not approved
+func FIPSMode() (enabled bool) {
+	return fips140.Enabled()
+}
+
+// CheckFIPSKey - returns an error wrapping `ErrNotFIPSApproved` in FIPS 140-3 mode if the key type is not approved
+func CheckFIPSKey(kt KeyType) (err error) {
+
+	if !FIPSMode() {
+		return
+	}
+
+	switch kt {
+	case ED25519, ECDSA256, ECDSA384, ECDSA521, RSA2048, RSA4096, RSA8192: // FIPS 186-5
+		return
+	}
+
+	return fmt.Errorf("%w: key type %s", ErrNotFIPSApproved, kt)
+}
+
+// CheckFIPSKeyX - returns an error wrapping `ErrNotFIPSApproved` in FIPS 140-3 mode if the key exchange type is not approved
+func CheckFIPSKeyX(kxt KeyXType) (err error) {
+
+	if !FIPSMode() {
+		return
+	}
+
+	switch kxt {
+	case ECDH256, ECDH384, ECDH521: // SP 800-56A
+		return
+	case CURVE25519:
+		return fmt.Errorf("%w: key exchange type %s is implemented by golang.org/x/crypto outside the FIPS module", ErrNotFIPSApproved, kxt)
+	}
+
+	return fmt.Errorf("%w: key exchange type %s", ErrNotFIPSApproved, kxt)
+}
+
+// CheckFIPS - returns an error wrapping `ErrNotFIPSApproved` in FIPS 140-3 mode, for operations that are never approved
+func CheckFIPS(operation string) (err error) {
+
+	if !FIPSMode() {
+		return
+	}
+
+	return fmt.Errorf("%w: %s", ErrNotFIPSApproved, operation)
+}
+
+// CheckFIPSOptions - returns an error wrapping `ErrNotFIPSApproved` in FIPS 140-3 mode if the options replace the approved randomness source,
+// checked when generating keys and when signing
+func (o *Options) CheckFIPSOptions() (err error) {
+
+	if o.IsDefaultRand() {
+		return
+	}
+
+	return CheckFIPS("keys must be generated and signatures made using the approved DRBG, not a custom randomness source")
+}
@@ -1,5 +1,10 @@
 package shared
 
+import (
+	"io"
+	"time"
+)
+
 // Key - interface for different types of asymetric keys
 type Key interface {
 	Bytes() (bytes []byte, err error)
@@ -10,9 +15,22 @@ type Key interface {
 	IsPrivateKey() (p bool)
 	IsPublicKey() (p bool)
 	KeyType() (kt KeyType)
-	Sign(hashed []byte) (signed []byte, err error)
+	Sign(hashed []byte, opts ...Option) (signed []byte, err error)
 	Verify(signed []byte, hashed []byte) (ok bool)
+	VerifyErr(signed []byte, hashed []byte) (err error)
+	SignMessage(msg []byte, opts ...Option) (signed []byte, err error)
+	VerifyMessage(msg []byte, signed []byte) (ok bool)
+	SignReader(rd io.Reader, opts ...Option) (signed []byte, err error)
+	VerifyReader(rd io.Reader, signed []byte) (ok bool)
 	MarshalJSON() (bytes []byte, err error)
-	//SetKeyID(kid string) (err error)
-	//GetKeyID() (kid string)
+	Equal(other Key) (ok bool)
+	PublicEqual(other Key) (ok bool)
+	Validate() (err error)
+	Destroy()
+	SetKeyID(kid string) (err error)
+	GetKeyID() (kid string)
+	SetValidity(v Validity)
+	Validity() (v Validity)
+	Uses() (uses uint64)
+	SetExpiryWarning(within time.Duration, fn ExpiryWarning)
 }
@@ -11,4 +11,8 @@ type KeyExchange interface {
 	IsPublicKey() (p bool)
 	KeyType() (kxt KeyXType)
 	Length() (length int)
+	Equal(other KeyExchange) (ok bool)
+	PublicEqual(other KeyExchange) (ok bool)
+	Validate() (err error)
+	Destroy()
 }
@@ -0,0 +1,169 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrKeyNotYetValid - wrapped by `ValidityError` when a key is used before its not-before time
+	ErrKeyNotYetValid = errors.New("key is not valid yet")
+
+	// ErrKeyExpired - wrapped by `ValidityError` when a key is used after its expiry time
+	ErrKeyExpired = errors.New("key has expired")
+
+	// ErrKeyUsageExceeded - wrapped by `ValidityError` when a key has reached its maximum number of uses
+	ErrKeyUsageExceeded = errors.New("key has reached its maximum number of uses")
+)
+
+// Validity - window during which a key may sign and an optional limit on the number of signatures.
+// `NotBefore` and `NotAfter` are written to the JWK as `nbf` and `exp`, the limit is only kept in memory.
+type Validity struct {
+	NotBefore time.Time // zero for no start
+	NotAfter  time.Time // zero for no expiry
+	MaxUses   uint64    // zero for unlimited
+}
+
+// ValidityError - returned by `Sign` when the key is outside its validity window or has reached its maximum number of uses
+type ValidityError struct {
+	Err      error // one of `ErrKeyNotYetValid`, `ErrKeyExpired` or `ErrKeyUsageExceeded`
+	Now      time.Time
+	Validity Validity
+	Uses     uint64
+}
+
+// Error - returns the reason the key cannot be used
+func (e *ValidityError) Error() (str string) {
+	switch e.Err {
+	case ErrKeyNotYetValid:
+		return fmt.Sprintf("%s: valid from %s", e.Err, e.Validity.NotBefore.Format(time.RFC3339))
+	case ErrKeyExpired:
+		return fmt.Sprintf("%s: expired at %s", e.Err, e.Validity.NotAfter.Format(time.RFC3339))
+	case ErrKeyUsageExceeded:
+		return fmt.Sprintf("%s: used %d of %d times", e.Err, e.Uses, e.Validity.MaxUses)
+	}
+
+	return fmt.Sprint(e.Err)
+}
+
+// Unwrap - allows `errors.Is` with the reason the key cannot be used
+func (e *ValidityError) Unwrap() (err error) {
+	return e.Err
+}
+
+// ExpiryWarning - called by `Sign` when a key is used within the warning period before it expires
+type ExpiryWarning func(notAfter time.Time)
+
+// Lifetime - validity window, usage counter and expiry warning of a key, kept in an unexported field by the key implementations
+// so only the key itself can count uses or write the validity to its JWK
+type Lifetime struct {
+	mu         sync.RWMutex // keys may be retired by one goroutine while another signs
+	validity   Validity
+	uses       atomic.Uint64
+	warnWithin time.Duration
+	warn       ExpiryWarning
+	clock      func() time.Time // defaults to `time.Now`
+}
+
+// SetValidity - sets the window during which the key may sign and its maximum number of uses
+func (l *Lifetime) SetValidity(v Validity) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.validity = v
+}
+
+// Validity - returns the window during which the key may sign and its maximum number of uses
+func (l *Lifetime) Validity() (v Validity) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.validity
+}
+
+// Uses - returns the number of signatures made with the key
+func (l *Lifetime) Uses() (uses uint64) {
+	return l.uses.Load()
+}
+
+// SetExpiryWarning - calls `fn` from `Sign` when the key is used `within` the given duration of its expiry
+func (l *Lifetime) SetExpiryWarning(within time.Duration, fn ExpiryWarning) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.warnWithin, l.warn = within, fn
+}
+
+// SetClock - sets the clock the validity window is checked against, `nil` uses `time.Now`
+func (l *Lifetime) SetClock(now func() time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.clock = now
+}
+
+// Use - checks the key may be used now and counts one use, called by the key implementations before signing.
+// The use is reserved so concurrent signers never exceed the limit, `Refund` gives it back when signing then fails.
+func (l *Lifetime) Use() (err error) {
+
+	l.mu.RLock()
+	v, warnWithin, warn, clock := l.validity, l.warnWithin, l.warn, l.clock
+	l.mu.RUnlock()
+
+	if nil == clock {
+		clock = time.Now
+	}
+	now := clock()
+
+	if !v.NotBefore.IsZero() && now.Before(v.NotBefore) {
+		return &ValidityError{Err: ErrKeyNotYetValid, Now: now, Validity: v, Uses: l.Uses()}
+	}
+
+	if !v.NotAfter.IsZero() && !now.Before(v.NotAfter) {
+		return &ValidityError{Err: ErrKeyExpired, Now: now, Validity: v, Uses: l.Uses()}
+	}
+
+	// the counter is only increased while below the limit so concurrent signers never exceed it
+	for {
+		uses := l.uses.Load()
+		if v.MaxUses != 0 && uses >= v.MaxUses {
+			return &ValidityError{Err: ErrKeyUsageExceeded, Now: now, Validity: v, Uses: uses}
+		}
+
+		if l.uses.CompareAndSwap(uses, uses+1) {
+			break
+		}
+	}
+
+	if nil != warn && !v.NotAfter.IsZero() && !now.Before(v.NotAfter.Add(-warnWithin)) {
+		warn(v.NotAfter)
+	}
+
+	return
+}
+
+// Refund - gives back a use counted by `Use` when the signature could not be made
+func (l *Lifetime) Refund() {
+	l.uses.Add(^uint64(0))
+}
+
+// WriteJWK - writes the validity window as the `nbf` and `exp` members of a JWK using its `Set` method
+func (l *Lifetime) WriteJWK(set func(name string, value any) error) (err error) {
+
+	v := l.Validity()
+
+	if !v.NotBefore.IsZero() {
+		if err = set("nbf", v.NotBefore.Unix()); nil != err {
+			return
+		}
+	}
+
+	if !v.NotAfter.IsZero() {
+		err = set("exp", v.NotAfter.Unix())
+	}
+
+	return
+}
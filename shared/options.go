@@ -0,0 +1,93 @@
+package shared
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"time"
+)
+
+// Options - settings that can be changed for generating keys and signing using `Option`
+type Options struct {
+	Rand   io.Reader        // source of randomness, defaults to `crypto/rand.Reader`
+	Strict bool             // validate keys when parsing, see `WithStrict`
+	Policy *Policy          // restricts the key types that may be generated or parsed, see `WithPolicy`
+	Clock  func() time.Time // clock generated and parsed keys check their validity against, see `WithClock`
+}
+
+// Option - functional option to change the default `Options`
+type Option func(o *Options)
+
+// maxScalarAttempts - limits rejection sampling so a broken randomness source fails instead of looping forever
+const maxScalarAttempts = 100
+
+// NewOptions - returns the default options with the given options applied
+func NewOptions(opts ...Option) (o *Options) {
+
+	o = &Options{Rand: rand.Reader, Clock: time.Now}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return
+}
+
+// WithRand - reads randomness from `rd` instead of `crypto/rand.Reader`.
+//
+// ED25519, ECDSA, Curve25519 and ECDH keys are generated from bytes read directly from `rd`. Since Go 1.26 the standard library
+// ignores custom readers for RSA key generation as well as ECDSA and RSA signing unless `GODEBUG=cryptocustomrand=1` is set.
+func WithRand(rd io.Reader) (opt Option) {
+	return func(o *Options) {
+		if nil != rd {
+			o.Rand = rd
+		}
+	}
+}
+
+// WithStrict - validates keys when parsing using `Validate`, and for private JWKs that the public members match the private key
+func WithStrict() (opt Option) {
+	return func(o *Options) {
+		o.Strict = true
+	}
+}
+
+// WithPolicy - denies generating or parsing key and key exchange types that violate the policy, returning a `*PolicyError`
+func WithPolicy(p *Policy) (opt Option) {
+	return func(o *Options) {
+		o.Policy = p
+	}
+}
+
+// WithClock - generated and parsed keys check their validity window against `now` instead of `time.Now` when signing, so it matches the clock that set it
+func WithClock(now func() time.Time) (opt Option) {
+	return func(o *Options) {
+		if nil != now {
+			o.Clock = now
+		}
+	}
+}
+
+// IsDefaultRand - returns if the options use `crypto/rand.Reader` as the source of randomness
+func (o *Options) IsDefaultRand() (ok bool) {
+	return o.Rand == rand.Reader
+}
+
+// SampleScalar - reads `size` bytes from `rd` until `parse` accepts them as a private scalar, `mask` clears the excess bits of the first byte
+func SampleScalar(rd io.Reader, size int, mask byte, parse func(b []byte) (err error)) (err error) {
+
+	b := make([]byte, size)
+	defer clear(b)
+
+	for range maxScalarAttempts {
+		if _, err = io.ReadFull(rd, b); nil != err {
+			return err
+		}
+		b[0] &= mask
+
+		if nil == parse(b) {
+			return nil
+		}
+	}
+
+	return errors.New("randomness source did not produce a valid private scalar")
+}
@@ -0,0 +1,134 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/svicknesh/enum2str"
+)
+
+// Operation - operation checked against a `Policy`
+type Operation uint8
+
+const (
+	// OpGenerate - generating a new key or key exchange
+	OpGenerate Operation = iota + 1
+
+	// OpImport - parsing an existing key or key exchange
+	OpImport
+)
+
+// String - returns string name for a given operation
+func (op Operation) String() (str string) {
+	return enum2str.String(op, "unknown", "generate", "import")
+}
+
+// ErrPolicyViolation - wrapped by every `PolicyError`
+var ErrPolicyViolation = errors.New("policy violation")
+
+// PolicyError - returned when a key or key exchange type is denied by a `Policy`
+type PolicyError struct {
+	Operation Operation
+	KeyType   KeyType  // set when a key was denied
+	KeyXType  KeyXType // set when a key exchange was denied
+	Reason    string
+}
+
+// Error - returns the reason the policy was violated
+func (e *PolicyError) Error() (str string) {
+	if e.KeyXType != 0 {
+		return fmt.Sprintf("%s: %s of %s denied: %s", ErrPolicyViolation, e.Operation, e.KeyXType, e.Reason)
+	}
+
+	return fmt.Sprintf("%s: %s of %s denied: %s", ErrPolicyViolation, e.Operation, e.KeyType, e.Reason)
+}
+
+// Unwrap - allows `errors.Is(err, ErrPolicyViolation)`
+func (e *PolicyError) Unwrap() (err error) {
+	return ErrPolicyViolation
+}
+
+// Policy - rules restricting the key and key exchange types that may be used, a type is allowed unless one of the rules denies it
+type Policy struct {
+	Rules []Rule
+}
+
+// Rule - restriction applied to the listed operations.
+// Sizes compare `KeyType.Bits` and `KeyXType.Bits`, which are the modulus size for RSA and the curve size otherwise.
+type Rule struct {
+	Operations []Operation // operations the rule applies to, all operations when empty
+
+	AllowKeyTypes []KeyType // when not empty, key types not listed are denied
+	DenyKeyTypes  []KeyType
+	MinKeyBits    int // key types smaller than this are denied
+
+	AllowKeyXTypes []KeyXType // when not empty, key exchange types not listed are denied
+	DenyKeyXTypes  []KeyXType
+	MinKeyXBits    int // key exchange types smaller than this are denied
+}
+
+// CheckKey - returns a `*PolicyError` if the key type is denied for the operation
+func (p *Policy) CheckKey(kt KeyType, op Operation) (err error) {
+
+	if nil == p {
+		return
+	}
+
+	for _, rule := range p.Rules {
+		if !rule.appliesTo(op) {
+			continue
+		}
+
+		var reason string
+		switch {
+		case len(rule.AllowKeyTypes) != 0 && !slices.Contains(rule.AllowKeyTypes, kt):
+			reason = "key type is not allowed"
+		case slices.Contains(rule.DenyKeyTypes, kt):
+			reason = "key type is denied"
+		case kt.Bits() < rule.MinKeyBits:
+			reason = fmt.Sprintf("key size %d bits is below the minimum of %d bits", kt.Bits(), rule.MinKeyBits)
+		default:
+			continue
+		}
+
+		return &PolicyError{Operation: op, KeyType: kt, Reason: reason}
+	}
+
+	return
+}
+
+// CheckKeyX - returns a `*PolicyError` if the key exchange type is denied for the operation
+func (p *Policy) CheckKeyX(kxt KeyXType, op Operation) (err error) {
+
+	if nil == p {
+		return
+	}
+
+	for _, rule := range p.Rules {
+		if !rule.appliesTo(op) {
+			continue
+		}
+
+		var reason string
+		switch {
+		case len(rule.AllowKeyXTypes) != 0 && !slices.Contains(rule.AllowKeyXTypes, kxt):
+			reason = "key exchange type is not allowed"
+		case slices.Contains(rule.DenyKeyXTypes, kxt):
+			reason = "key exchange type is denied"
+		case kxt.Bits() < rule.MinKeyXBits:
+			reason = fmt.Sprintf("key exchange size %d bits is below the minimum of %d bits", kxt.Bits(), rule.MinKeyXBits)
+		default:
+			continue
+		}
+
+		return &PolicyError{Operation: op, KeyXType: kxt, Reason: reason}
+	}
+
+	return
+}
+
+// appliesTo - returns if the rule applies to the operation
+func (r Rule) appliesTo(op Operation) (ok bool) {
+	return len(r.Operations) == 0 || slices.Contains(r.Operations, op)
+}
@@ -0,0 +1,33 @@
+package shared
+
+import (
+	"crypto/cipher"
+	"io"
+	"time"
+)
+
+// SymmetricKey - interface for symmetric `oct` keys, kept apart from `Key` since they have no public key
+type SymmetricKey interface {
+	Bytes() (bytes []byte, err error)
+	String() (str string)
+	SecretKeyInstance() (secret []byte)
+	KeyType() (st SymKeyType)
+	Sign(data []byte, opts ...Option) (signed []byte, err error)
+	Verify(signed []byte, data []byte) (ok bool)
+	VerifyErr(signed []byte, data []byte) (err error)
+	SignMessage(msg []byte, opts ...Option) (signed []byte, err error)
+	VerifyMessage(msg []byte, signed []byte) (ok bool)
+	SignReader(rd io.Reader, opts ...Option) (signed []byte, err error)
+	VerifyReader(rd io.Reader, signed []byte) (ok bool)
+	AEAD() (aead cipher.AEAD, err error)
+	MarshalJSON() (bytes []byte, err error)
+	Equal(other SymmetricKey) (ok bool)
+	Validate() (err error)
+	Destroy()
+	SetKeyID(kid string) (err error)
+	GetKeyID() (kid string)
+	SetValidity(v Validity)
+	Validity() (v Validity)
+	Uses() (uses uint64)
+	SetExpiryWarning(within time.Duration, fn ExpiryWarning)
+}
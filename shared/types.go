@@ -1,6 +1,9 @@
 package shared
 
 import (
+	"crypto"
+	_ "crypto/sha256" // registers SHA-256 for `KeyType.Hash`
+	_ "crypto/sha512" // registers SHA-384 and SHA-512 for `KeyType.Hash`
 	"encoding/json"
 	"fmt"
 	"strconv"
@@ -9,8 +12,9 @@ import (
 	"github.com/svicknesh/enum2str"
 )
 
-type KeyType uint8  // new type to define key types to be generated
-type KeyXType uint8 // new type to define key exchanges to be generated
+type KeyType uint8    // new type to define key types to be generated
+type KeyXType uint8   // new type to define key exchanges to be generated
+type SymKeyType uint8 // new type to define symmetric keys to be generated
 
 const (
 
@@ -50,6 +54,23 @@ const (
 	ECDH521
 )
 
+const (
+	// HS256 - generate a 256 bit symmetric key for HMAC using SHA-256
+	HS256 SymKeyType = iota + 1
+
+	// HS384 - generate a 384 bit symmetric key for HMAC using SHA-384
+	HS384
+
+	// HS512 - generate a 512 bit symmetric key for HMAC using SHA-512
+	HS512
+
+	// A128GCM - generate a 128 bit symmetric key for AES-GCM
+	A128GCM
+
+	// A256GCM - generate a 256 bit symmetric key for AES-GCM
+	A256GCM
+)
+
 var keyTypeMap = map[string]KeyType{
 	"ed25519":  ED25519,
 	"ecdsa256": ECDSA256,
@@ -67,6 +88,14 @@ var keyXTypeMap = map[string]KeyXType{
 	"ecdh521":    ECDH521,
 }
 
+var symKeyTypeMap = map[string]SymKeyType{
+	"hs256":   HS256,
+	"hs384":   HS384,
+	"hs512":   HS512,
+	"a128gcm": A128GCM,
+	"a256gcm": A256GCM,
+}
+
 // String - returns string name for a given key type
 func (kt KeyType) String() (str string) {
 	return enum2str.String(kt, "unknown", "ed25519", "ecdsa256", "ecdsa384", "ecdsa521", "rsa2048", "rsa4096", "rsa8192")
@@ -77,6 +106,89 @@ func (kx KeyXType) String() (str string) {
 	return enum2str.String(kx, "unknown", "curve25519", "ecdh256", "ecdh384", "ecdh521")
 }
 
+// String - returns string name for a given symmetric key type
+func (st SymKeyType) String() (str string) {
+	return enum2str.String(st, "unknown", "hs256", "hs384", "hs512", "a128gcm", "a256gcm")
+}
+
+// Hash - returns the digest used for message signing by a given key type, ED25519 signs messages directly and returns 0
+func (kt KeyType) Hash() (h crypto.Hash) {
+	switch kt {
+	case ECDSA256, RSA2048:
+		return crypto.SHA256
+	case ECDSA384, RSA4096:
+		return crypto.SHA384
+	case ECDSA521, RSA8192:
+		return crypto.SHA512
+	}
+
+	return
+}
+
+// Hash - returns the digest used for HMAC by a given symmetric key type, AES-GCM keys do not sign and return 0
+func (st SymKeyType) Hash() (h crypto.Hash) {
+	switch st {
+	case HS256:
+		return crypto.SHA256
+	case HS384:
+		return crypto.SHA384
+	case HS512:
+		return crypto.SHA512
+	}
+
+	return
+}
+
+// Bits - returns the nominal size in bits of a given key type, the modulus size for RSA and the curve size otherwise
+func (kt KeyType) Bits() (bits int) {
+	switch kt {
+	case ED25519, ECDSA256:
+		return 256
+	case ECDSA384:
+		return 384
+	case ECDSA521:
+		return 521
+	case RSA2048:
+		return 2048
+	case RSA4096:
+		return 4096
+	case RSA8192:
+		return 8192
+	}
+
+	return
+}
+
+// Bits - returns the size in bits of the secret of a given symmetric key type
+func (st SymKeyType) Bits() (bits int) {
+	switch st {
+	case A128GCM:
+		return 128
+	case HS256, A256GCM:
+		return 256
+	case HS384:
+		return 384
+	case HS512:
+		return 512
+	}
+
+	return
+}
+
+// Bits - returns the nominal size in bits of the curve used by a given key exchange type
+func (kx KeyXType) Bits() (bits int) {
+	switch kx {
+	case CURVE25519, ECDH256:
+		return 256
+	case ECDH384:
+		return 384
+	case ECDH521:
+		return 521
+	}
+
+	return
+}
+
 // MarshalJSON - serializes the `KeyType` as a JSON string.
 func (kt KeyType) MarshalJSON() ([]byte, error) {
 	return json.Marshal(kt.String())
@@ -125,3 +237,27 @@ func GetKeyXType(name string) KeyXType {
 	}
 	return KeyXType(0)
 }
+
+// MarshalJSON - serializes the `SymKeyType` as a JSON string.
+func (st SymKeyType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(st.String())
+}
+
+// UnmarshalJSON - deserializes a JSON string into a `SymKeyType`.
+func (st *SymKeyType) UnmarshalJSON(data []byte) error {
+	strVal, err := strconv.Unquote(string(data))
+	if err != nil {
+		return fmt.Errorf("SymKeyType.UnmarshalJSON: invalid JSON string (len=%d) → %w", len(data), err)
+	}
+
+	*st = GetSymKeyType(strVal)
+	return nil
+}
+
+// GetSymKeyType - returns symmetric key type from its name
+func GetSymKeyType(name string) SymKeyType {
+	if st, ok := symKeyTypeMap[strings.ToLower(name)]; ok {
+		return st
+	}
+	return SymKeyType(0)
+}
@@ -0,0 +1,122 @@
+package oct
+
+import (
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"github.com/svicknesh/key/v2/shared"
+)
+
+// Generate - generates a new random symmetric key of the given type
+func Generate(kt shared.SymKeyType, opts ...shared.Option) (k *K, err error) {
+
+	if kt.Bits() == 0 {
+		return nil, fmt.Errorf("oct-generate: unsupported key type %s for symmetric generation", kt)
+	}
+
+	o := shared.NewOptions(opts...)
+
+	k = &K{kt: kt, secret: make([]byte, kt.Bits()/8)}
+	if _, err = io.ReadFull(o.Rand, k.secret); nil != err {
+		return nil, fmt.Errorf("oct-generate: error generating %s key -> %w", kt, err)
+	}
+	k.lifetime.SetClock(o.Clock)
+
+	return
+}
+
+// New - returns a symmetric key of the given type holding a copy of `secret`.
+// HMAC keys must be at least as long as the hash output (RFC 7518 section 3.2), AES-GCM keys must be exactly the key size.
+// `shared.WithClock` sets the clock its validity window is checked against.
+func New(secret []byte, kt shared.SymKeyType, opts ...shared.Option) (k *K, err error) {
+
+	if err = checkSize(kt, len(secret)); nil != err {
+		return nil, fmt.Errorf("oct-new: %w", err)
+	}
+
+	k = &K{kt: kt, secret: append([]byte(nil), secret...)}
+	k.lifetime.SetClock(shared.NewOptions(opts...).Clock)
+
+	return
+}
+
+// Derive - derives a symmetric key of the given type from input keying material such as the output of `SharedSecret`, using HKDF-SHA256 (RFC 5869).
+// The same secret, salt and info always produce the same key, a different `info` gives an independent key for each purpose.
+func Derive(kt shared.SymKeyType, secret, salt []byte, info string) (k *K, err error) {
+
+	if kt.Bits() == 0 {
+		return nil, fmt.Errorf("oct-derive: unsupported key type %s for symmetric derivation", kt)
+	}
+
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("oct-derive: empty secret")
+	}
+
+	k = &K{kt: kt}
+	if k.secret, err = hkdf.Key(sha256.New, secret, salt, info, kt.Bits()/8); nil != err {
+		return nil, fmt.Errorf("oct-derive: %w", err)
+	}
+
+	return
+}
+
+// checkSize - makes sure the secret is long enough for the key type
+func checkSize(kt shared.SymKeyType, size int) (err error) {
+
+	switch kt {
+	case shared.HS256, shared.HS384, shared.HS512:
+		if size < kt.Bits()/8 {
+			return fmt.Errorf("%s key must be at least %d bytes, got %d", kt, kt.Bits()/8, size)
+		}
+	case shared.A128GCM, shared.A256GCM:
+		if size != kt.Bits()/8 {
+			return fmt.Errorf("%s key must be %d bytes, got %d", kt, kt.Bits()/8, size)
+		}
+	default:
+		return fmt.Errorf("unsupported key type %s for symmetric keys", kt)
+	}
+
+	return
+}
+
+// algorithm - returns the JWA algorithm name of HMAC key types, used as the JWK `alg` member.
+// `A128GCM` and `A256GCM` are JWE `enc` values, not `alg` values, so AES-GCM keys return an empty name and are written without `alg`.
+func algorithm(kt shared.SymKeyType) (alg string) {
+	switch kt {
+	case shared.HS256:
+		return "HS256"
+	case shared.HS384:
+		return "HS384"
+	case shared.HS512:
+		return "HS512"
+	}
+
+	return
+}
+
+// CheckAlgorithm - checks the `alg` member of a JWK allows it to be used as the given key type, a missing `alg` is allowed.
+// HMAC keys must have their own algorithm, AES-GCM keys may have `dir` or the AES-GCM key wrap algorithm of their size.
+func CheckAlgorithm(kt shared.SymKeyType, alg string) (err error) {
+
+	switch {
+	case alg == "", alg == algorithm(kt):
+		return
+	case kt == shared.A128GCM && (alg == "dir" || alg == "A128GCMKW"):
+		return
+	case kt == shared.A256GCM && (alg == "dir" || alg == "A256GCMKW"):
+		return
+	}
+
+	return fmt.Errorf("algorithm %q cannot be used by a %s key", alg, kt)
+}
+
+// defaultKeyID - returns a key ID that identifies the secret without revealing a plain hash of it
+func defaultKeyID(secret []byte) (kid string) {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte("svicknesh/key oct kid"))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
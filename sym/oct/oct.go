@@ -0,0 +1,274 @@
+package oct
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/svicknesh/key/v2/shared"
+)
+
+type K struct {
+	secret    []byte
+	kt        shared.SymKeyType
+	kid       string
+	destroyed bool
+
+	lifetime shared.Lifetime // validity window and usage counter enforced by `Sign`
+}
+
+// Bytes - returns JSON encoded bytes of the key, a JWK with `kty` oct and for HMAC keys `alg` set to the key type
+func (k *K) Bytes() (bytes []byte, err error) {
+
+	if k.destroyed {
+		return nil, fmt.Errorf("oct-bytes: %w", shared.ErrKeyDestroyed)
+	}
+
+	jk, err := jwk.Import(k.secret)
+	if nil != err {
+		return nil, fmt.Errorf("oct-bytes: error importing raw key -> %w", err)
+	}
+
+	if alg := algorithm(k.kt); alg != "" {
+		if err = jk.Set(jwk.AlgorithmKey, alg); nil != err {
+			return nil, fmt.Errorf("oct-bytes: error setting algorithm -> %w", err)
+		}
+	}
+
+	kid := k.kid
+	if len(kid) == 0 {
+		// the JWK thumbprint is a plain hash of the secret, so the default key ID is derived with the secret as an HMAC key instead
+		kid = defaultKeyID(k.secret)
+	}
+	if err = jk.Set(jwk.KeyIDKey, kid); nil != err {
+		return nil, fmt.Errorf("oct-bytes: error setting key id -> %w", err)
+	}
+
+	if err = k.lifetime.WriteJWK(jk.Set); nil != err {
+		return nil, fmt.Errorf("oct-bytes: error setting validity -> %w", err)
+	}
+
+	return json.Marshal(jk)
+}
+
+// String - returns JSON encoded string of the key
+func (k *K) String() (str string) {
+	kb, _ := k.Bytes()
+	return string(kb)
+}
+
+// SecretKeyInstance - returns the secret key bytes, nil once the key is destroyed
+func (k *K) SecretKeyInstance() (secret []byte) {
+	return k.secret
+}
+
+// KeyType - returns key type
+func (k *K) KeyType() (st shared.SymKeyType) {
+	return k.kt
+}
+
+// Sign - computes the HMAC of the given data, HMAC needs no digest so `hashed` may be any data. AES-GCM keys cannot sign.
+func (k *K) Sign(hashed []byte, opts ...shared.Option) (signed []byte, err error) {
+
+	mac, err := k.newMAC()
+	if nil != err {
+		return nil, fmt.Errorf("oct-sign: %w", err)
+	}
+
+	if err = k.lifetime.Use(); nil != err {
+		return nil, fmt.Errorf("oct-sign: %w", err)
+	}
+
+	mac.Write(hashed)
+
+	return mac.Sum(nil), nil
+}
+
+// Verify - verifies the HMAC of the given data
+func (k *K) Verify(signed []byte, hashed []byte) (ok bool) {
+	return k.VerifyErr(signed, hashed) == nil
+}
+
+// VerifyErr - verifies the HMAC of the given data in constant time, returning the reason for failure
+func (k *K) VerifyErr(signed []byte, hashed []byte) (err error) {
+
+	mac, err := k.newMAC()
+	if nil != err {
+		return fmt.Errorf("oct-verify: %w", err)
+	}
+
+	mac.Write(hashed)
+
+	return checkMAC(mac, signed)
+}
+
+// SignMessage - computes the HMAC of the given message, as used by JWS HS256, HS384 and HS512
+func (k *K) SignMessage(msg []byte, opts ...shared.Option) (signed []byte, err error) {
+	return k.Sign(msg, opts...)
+}
+
+// VerifyMessage - verifies the HMAC of the given message
+func (k *K) VerifyMessage(msg []byte, signed []byte) (ok bool) {
+	return k.Verify(signed, msg)
+}
+
+// SignReader - computes the HMAC of the message read from `rd` without reading it into memory
+func (k *K) SignReader(rd io.Reader, opts ...shared.Option) (signed []byte, err error) {
+
+	mac, err := k.newMAC()
+	if nil != err {
+		return nil, fmt.Errorf("oct-signreader: %w", err)
+	}
+
+	if err = k.lifetime.Use(); nil != err {
+		return nil, fmt.Errorf("oct-signreader: %w", err)
+	}
+
+	if _, err = io.Copy(mac, rd); nil != err {
+		k.lifetime.Refund() // a failed signature does not count against the usage limit
+		return nil, fmt.Errorf("oct-signreader: error reading message -> %w", err)
+	}
+
+	return mac.Sum(nil), nil
+}
+
+// VerifyReader - verifies the HMAC of the message read from `rd` without reading it into memory
+func (k *K) VerifyReader(rd io.Reader, signed []byte) (ok bool) {
+
+	mac, err := k.newMAC()
+	if nil != err {
+		return false
+	}
+
+	if _, err = io.Copy(mac, rd); nil != err {
+		return false
+	}
+
+	return checkMAC(mac, signed) == nil
+}
+
+// AEAD - returns AES-GCM keyed with an A128GCM or A256GCM key, nonces must never repeat for the same key.
+// Nonces chosen by the caller are not approved in FIPS 140-3 mode, where this fails.
+func (k *K) AEAD() (aead cipher.AEAD, err error) {
+
+	if k.destroyed {
+		return nil, fmt.Errorf("oct-aead: %w", shared.ErrKeyDestroyed)
+	}
+
+	if k.kt != shared.A128GCM && k.kt != shared.A256GCM {
+		return nil, fmt.Errorf("oct-aead: %s keys are not AES-GCM keys", k.kt)
+	}
+
+	if err = shared.CheckFIPS("AES-GCM with nonces chosen by the caller, use `Encrypt` instead"); nil != err {
+		return nil, fmt.Errorf("oct-aead: %w", err)
+	}
+
+	block, err := aes.NewCipher(k.secret)
+	if nil != err {
+		return nil, fmt.Errorf("oct-aead: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// MarshalJSON - marshals this Key into a JSON
+func (k *K) MarshalJSON() (bytes []byte, err error) {
+	return k.Bytes()
+}
+
+// SetKeyID - sets a custom key ID `kid` for the key
+func (k *K) SetKeyID(kid string) (err error) {
+	k.kid = kid
+	return
+}
+
+// GetKeyID - returns the key ID `kid` from the key
+func (k *K) GetKeyID() (kid string) {
+	return k.kid
+}
+
+// SetValidity - sets the window during which the key may sign and its maximum number of uses
+func (k *K) SetValidity(v shared.Validity) {
+	k.lifetime.SetValidity(v)
+}
+
+// Validity - returns the window during which the key may sign and its maximum number of uses
+func (k *K) Validity() (v shared.Validity) {
+	return k.lifetime.Validity()
+}
+
+// Uses - returns the number of signatures made with the key
+func (k *K) Uses() (uses uint64) {
+	return k.lifetime.Uses()
+}
+
+// SetExpiryWarning - calls `fn` from `Sign` when the key is used `within` the given duration of its expiry
+func (k *K) SetExpiryWarning(within time.Duration, fn shared.ExpiryWarning) {
+	k.lifetime.SetExpiryWarning(within, fn)
+}
+
+// Destroy - zeroes the secret, every later use of the key fails
+func (k *K) Destroy() {
+	clear(k.secret)
+	k.secret = nil
+	k.destroyed = true
+}
+
+// Equal - returns if `other` is a symmetric key of the same type holding the same secret in constant time, the key ID is ignored
+func (k *K) Equal(other shared.SymmetricKey) (ok bool) {
+	if nil == other || other.KeyType() != k.kt || k.destroyed {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(k.secret, other.SecretKeyInstance()) == 1
+}
+
+// Validate - checks the secret is the right size for the key type
+func (k *K) Validate() (err error) {
+
+	if k.destroyed {
+		return fmt.Errorf("oct-validate: %w", shared.ErrKeyDestroyed)
+	}
+
+	if err = checkSize(k.kt, len(k.secret)); nil != err {
+		return fmt.Errorf("oct-validate: %w: %w", shared.ErrInvalidKey, err)
+	}
+
+	return
+}
+
+// newMAC - returns HMAC keyed with the secret using the hash of the key type
+func (k *K) newMAC() (mac hash.Hash, err error) {
+
+	if k.destroyed {
+		return nil, shared.ErrKeyDestroyed
+	}
+
+	h := k.kt.Hash()
+	if h == 0 {
+		return nil, fmt.Errorf("%s keys are for encryption, not signing", k.kt)
+	}
+
+	return hmac.New(h.New, k.secret), nil
+}
+
+// checkMAC - compares the computed HMAC with the given one in constant time
+func checkMAC(mac hash.Hash, signed []byte) (err error) {
+
+	if len(signed) != mac.Size() {
+		return fmt.Errorf("oct-verify: %w: expected %d bytes, got %d", shared.ErrMalformedSignature, mac.Size(), len(signed))
+	}
+
+	if !hmac.Equal(mac.Sum(nil), signed) {
+		return fmt.Errorf("oct-verify: %w", shared.ErrSignatureMismatch)
+	}
+
+	return
+}
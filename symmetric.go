@@ -0,0 +1,97 @@
+package key
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lestrrat-go/jwx/v3/jwk"
+	"github.com/svicknesh/key/v2/shared"
+	"github.com/svicknesh/key/v2/sym/oct"
+)
+
+// GenerateSymmetricKey - generates a new random symmetric key, options such as `WithRand` change how it is generated
+func GenerateSymmetricKey(st shared.SymKeyType, opts ...Option) (k shared.SymmetricKey, err error) {
+
+	o := shared.NewOptions(opts...)
+
+	if err = o.CheckFIPSOptions(); nil != err {
+		return nil, fmt.Errorf("generatesymmetrickey: %w", err)
+	}
+
+	if k, err = oct.Generate(st, opts...); nil != err {
+		return nil, fmt.Errorf("generatesymmetrickey: %w", err)
+	}
+
+	return
+}
+
+// NewSymmetricKeyFromBytes - returns new instance of symmetric key of the given type from JWK bytes with `kty` oct, `WithStrict` validates the key before it is returned.
+// The size of a symmetric key does not tell HMAC and AES-GCM keys apart and `alg` is optional, so the caller gives the type and an `alg` that does not match it is refused.
+func NewSymmetricKeyFromBytes(jwkBytes []byte, st shared.SymKeyType, opts ...Option) (k shared.SymmetricKey, err error) {
+
+	meta := new(jwkMeta)
+	if err = json.Unmarshal(jwkBytes, meta); nil != err {
+		return nil, fmt.Errorf("newsymmetrickeyfrombytes: JWK key unmarshal error -> %w", err)
+	}
+
+	var secret []byte
+	if err = jwk.ParseRawKey(jwkBytes, &secret); nil != err {
+		return nil, fmt.Errorf("newsymmetrickeyfrombytes: %w", err)
+	}
+	defer clear(secret)
+
+	if err = oct.CheckAlgorithm(st, meta.Algorithm); nil != err {
+		return nil, fmt.Errorf("newsymmetrickeyfrombytes: %w", err)
+	}
+
+	if k, err = oct.New(secret, st, opts...); nil != err {
+		return nil, fmt.Errorf("newsymmetrickeyfrombytes: %w", err)
+	}
+
+	k.SetKeyID(meta.KeyID)
+
+	var v shared.Validity
+	if meta.NotBefore != 0 {
+		v.NotBefore = time.Unix(meta.NotBefore, 0)
+	}
+	if meta.NotAfter != 0 {
+		v.NotAfter = time.Unix(meta.NotAfter, 0)
+	}
+	k.SetValidity(v)
+
+	if o := shared.NewOptions(opts...); o.Strict {
+		if err = k.Validate(); nil != err {
+			return nil, fmt.Errorf("newsymmetrickeyfrombytes: %w", err)
+		}
+	}
+
+	return
+}
+
+// NewSymmetricKeyFromStr - returns new instance of symmetric key of the given type from a JWK string, see `NewSymmetricKeyFromBytes`
+func NewSymmetricKeyFromStr(jwkStr string, st shared.SymKeyType, opts ...Option) (k shared.SymmetricKey, err error) {
+	return NewSymmetricKeyFromBytes([]byte(jwkStr), st, opts...)
+}
+
+// DeriveSymmetricKey - derives a symmetric key from the shared secret of a private key exchange and the peer's public key exchange using HKDF-SHA256,
+// both peers derive the same key when they use the same salt and info
+func DeriveSymmetricKey(st shared.SymKeyType, kx, kxPeer shared.KeyExchange, salt []byte, info string) (k shared.SymmetricKey, err error) {
+
+	if nil == kx || nil == kxPeer {
+		return nil, errors.New("derivesymmetrickey: a private and a peer key exchange are required")
+	}
+
+	secret, err := kx.SharedSecret(kxPeer)
+	if nil != err {
+		return nil, fmt.Errorf("derivesymmetrickey: %w", err)
+	}
+	defer clear(secret)
+
+	if k, err = oct.Derive(st, secret, salt, info); nil != err {
+		return nil, fmt.Errorf("derivesymmetrickey: %w", err)
+	}
+
+	return
+}